@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Key adalah satu kunci penandatanganan JWT yang diidentifikasi dengan kid.
+// Kunci tanpa private key (signKey nil) hanya dipakai untuk verifikasi.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// KeySet berisi kunci aktif untuk menandatangani token baru serta kunci
+// lama (retired) yang masih diterima saat verifikasi.
+type KeySet struct {
+	active *Key
+	keys   map[string]*Key
+}
+
+// Keys adalah key set yang dipakai aplikasi, diisi oleh LoadKeys.
+var Keys *KeySet
+
+// LoadKeys memuat kunci dari konfigurasi environment:
+//
+//   - JWT_KEYS_DIR: direktori berisi <kid>.pem (private key RSA/Ed25519, atau
+//     public key untuk kunci yang sudah dipensiunkan) dan <kid>.secret (HS256)
+//   - JWT_ACTIVE_KID: kid yang dipakai untuk menandatangani token baru
+//   - JWT_SECRET: fallback satu kunci HS256 dengan kid "default"
+func LoadKeys() error {
+	ks := &KeySet{keys: map[string]*Key{}}
+
+	if dir := os.Getenv("JWT_KEYS_DIR"); dir != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+
+			ext := filepath.Ext(entry.Name())
+			kid := strings.TrimSuffix(entry.Name(), ext)
+			if ext != ".pem" && ext != ".secret" {
+				continue
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return err
+			}
+
+			var key *Key
+			if ext == ".secret" {
+				key, err = newHMACKey(kid, data)
+			} else {
+				key, err = parsePEMKey(kid, data)
+			}
+			if err != nil {
+				return fmt.Errorf("key %q: %w", kid, err)
+			}
+			ks.keys[kid] = key
+		}
+	}
+
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		if _, exists := ks.keys["default"]; !exists {
+			key, err := newHMACKey("default", []byte(secret))
+			if err != nil {
+				return err
+			}
+			ks.keys["default"] = key
+		}
+	}
+
+	if len(ks.keys) == 0 {
+		return errors.New("no signing keys configured, set JWT_KEYS_DIR or JWT_SECRET")
+	}
+
+	activeKid := os.Getenv("JWT_ACTIVE_KID")
+	if activeKid == "" {
+		if len(ks.keys) > 1 {
+			return errors.New("JWT_ACTIVE_KID is required when more than one key is configured")
+		}
+		for kid := range ks.keys {
+			activeKid = kid
+		}
+	}
+
+	active, ok := ks.keys[activeKid]
+	if !ok {
+		return fmt.Errorf("active key %q not found", activeKid)
+	}
+	if active.signKey == nil {
+		return fmt.Errorf("active key %q has no private key", activeKid)
+	}
+	ks.active = active
+
+	Keys = ks
+	return nil
+}
+
+func newHMACKey(kid string, data []byte) (*Key, error) {
+	secret := []byte(strings.TrimSpace(string(data)))
+	if len(secret) < 32 {
+		return nil, errors.New("HMAC secret must be at least 32 bytes")
+	}
+
+	return &Key{ID: kid, Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+}
+
+func parsePEMKey(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodRS256, signKey: k, verifyKey: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &Key{ID: kid, Method: jwt.SigningMethodRS256, verifyKey: k}, nil
+	case ed25519.PrivateKey:
+		return &Key{ID: kid, Method: SigningMethodEd25519, signKey: k, verifyKey: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: kid, Method: SigningMethodEd25519, verifyKey: k}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %T", parsed)
+}
+
+// Sign menandatangani claims dengan kunci aktif dan menambahkan header kid.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.Method, claims)
+	token.Header["kid"] = ks.active.ID
+	return token.SignedString(ks.active.signKey)
+}
+
+// Parse memverifikasi token dengan kunci sesuai kid-nya. Algoritma token harus
+// sama dengan algoritma kunci untuk mencegah serangan algorithm confusion.
+func (ks *KeySet) Parse(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %q", token.Method.Alg())
+		}
+		return key.verifyKey, nil
+	})
+}
+
+// JWK adalah representasi public key dalam format JSON Web Key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS mengembalikan semua public key (aktif dan retired). Kunci HMAC tidak
+// pernah dipublikasikan.
+func (ks *KeySet) JWKS() []JWK {
+	jwks := []JWK{}
+	for _, key := range ks.keys {
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks = append(jwks, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].Kid < jwks[j].Kid })
+	return jwks
+}
+
+// Sign menandatangani claims dengan key set aplikasi.
+func Sign(claims jwt.Claims) (string, error) {
+	return Keys.Sign(claims)
+}
+
+// Parse memverifikasi token dengan key set aplikasi.
+func Parse(tokenString string) (*jwt.Token, error) {
+	return Keys.Parse(tokenString)
+}
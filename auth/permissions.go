@@ -0,0 +1,37 @@
+package auth
+
+// Role yang dikenal aplikasi, disimpan di kolom role pada tabel users.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Permission yang dimiliki role di luar kepemilikan resource.
+const (
+	PermModeratePhotos   = "photos:moderate"
+	PermModerateComments = "comments:moderate"
+	PermManageUsers      = "users:manage"
+)
+
+var rolePermissions = map[string][]string{
+	RoleUser:      {},
+	RoleModerator: {PermModeratePhotos, PermModerateComments},
+	RoleAdmin:     {PermModeratePhotos, PermModerateComments, PermManageUsers},
+}
+
+// ValidRole mengembalikan true jika role dikenal.
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission mengembalikan true jika role memiliki permission.
+func HasPermission(role, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package auth
+
+// Scope yang bisa diberikan ke personal access token.
+const (
+	ScopePhotosRead         = "photos:read"
+	ScopePhotosWrite        = "photos:write"
+	ScopeCommentsRead       = "comments:read"
+	ScopeCommentsWrite      = "comments:write"
+	ScopeSocialMediasRead   = "socialmedias:read"
+	ScopeSocialMediasWrite  = "socialmedias:write"
+	ScopeUsersRead          = "users:read"
+	ScopeUsersWrite         = "users:write"
+	ScopeNotificationsRead  = "notifications:read"
+	ScopeNotificationsWrite = "notifications:write"
+)
+
+// PersonalAccessTokenPrefix menandai token API agar bisa dibedakan dari JWT.
+const PersonalAccessTokenPrefix = "mgp_"
+
+// Scopes berisi semua scope yang dikenal.
+var Scopes = []string{
+	ScopePhotosRead,
+	ScopePhotosWrite,
+	ScopeCommentsRead,
+	ScopeCommentsWrite,
+	ScopeSocialMediasRead,
+	ScopeSocialMediasWrite,
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeNotificationsRead,
+	ScopeNotificationsWrite,
+}
+
+// ValidScope mengembalikan true jika scope dikenal.
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
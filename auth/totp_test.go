@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Secret ASCII "12345678901234567890" dari RFC 4226 dan RFC 6238
+var rfcSecret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeRFC4226(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		if got := totpCode([]byte("12345678901234567890"), int64(counter)); got != code {
+			t.Errorf("totpCode(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestValidateTOTPRFC6238(t *testing.T) {
+	// Enam digit terakhir dari vektor SHA1 RFC 6238
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		step, ok := ValidateTOTP(rfcSecret, tt.code, time.Unix(tt.unix, 0), 0)
+		if !ok || step != tt.unix/totpPeriod {
+			t.Errorf("ValidateTOTP at %d = %d, %v", tt.unix, step, ok)
+		}
+	}
+}
+
+func TestValidateTOTPWindowAndReplay(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / totpPeriod
+	key := []byte("12345678901234567890")
+
+	for offset := int64(-1); offset <= 1; offset++ {
+		if _, ok := ValidateTOTP(rfcSecret, totpCode(key, current+offset), now, 0); !ok {
+			t.Errorf("code for step offset %d rejected", offset)
+		}
+	}
+	for _, offset := range []int64{-2, 2} {
+		if _, ok := ValidateTOTP(rfcSecret, totpCode(key, current+offset), now, 0); ok {
+			t.Errorf("code for step offset %d accepted", offset)
+		}
+	}
+
+	code := totpCode(key, current)
+	step, ok := ValidateTOTP(rfcSecret, code, now, 0)
+	if !ok {
+		t.Fatal("valid code rejected")
+	}
+	if _, ok := ValidateTOTP(rfcSecret, code, now, step); ok {
+		t.Error("replayed code accepted")
+	}
+
+	// Secret dengan huruf kecil dan spasi tetap diterima
+	if _, ok := ValidateTOTP(" "+strings.ToLower(rfcSecret)+" ", code, now, 0); !ok {
+		t.Error("lower-case secret rejected")
+	}
+
+	for _, bad := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok := ValidateTOTP(rfcSecret, bad, now, 0); ok {
+			t.Errorf("code %q accepted", bad)
+		}
+	}
+	if _, ok := ValidateTOTP("not base32!", code, now, 0); ok {
+		t.Error("invalid secret accepted")
+	}
+}
+
+func TestGenerateTOTPSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secret %q decodes to %d bytes, %v", secret, len(key), err)
+	}
+
+	now := time.Now()
+	if _, ok := ValidateTOTP(secret, totpCode(key, now.Unix()/totpPeriod), now, 0); !ok {
+		t.Error("code for generated secret rejected")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	u, err := url.Parse(TOTPURI("MyGram", "ana@example.com", "ABC"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/MyGram:ana@example.com" ||
+		q.Get("secret") != "ABC" || q.Get("issuer") != "MyGram" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Errorf("TOTPURI = %s", u)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' || seen[code] {
+			t.Errorf("unexpected recovery code %q", code)
+		}
+		seen[code] = true
+	}
+
+	if HashRecoveryCode("ABCDE-12345") != HashRecoveryCode(" abcde12345 ") {
+		t.Error("HashRecoveryCode does not normalize case, dashes and spaces")
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Mikael88/go-mygram/auth"
+	"github.com/Mikael88/go-mygram/models"
+)
+
+// BootstrapRoles memberi role admin dan moderator ke akun yang email-nya ada
+// di ADMIN_EMAILS dan MODERATOR_EMAILS (dipisah koma). Ini satu-satunya cara
+// membuat admin pertama karena registrasi selalu memberi role user. Email harus
+// sudah diverifikasi agar orang lain tidak bisa mendaftar lebih dulu dengan
+// email tersebut. Role hanya dinaikkan, admin tidak diturunkan menjadi moderator.
+func BootstrapRoles() {
+	promote(auth.RoleAdmin, envEmails("ADMIN_EMAILS"), []string{auth.RoleUser, auth.RoleModerator})
+	promote(auth.RoleModerator, envEmails("MODERATOR_EMAILS"), []string{auth.RoleUser})
+}
+
+func promote(role string, emails []string, from []string) {
+	if len(emails) == 0 {
+		return
+	}
+
+	result := DB.Model(&models.User{}).
+		Where("LOWER(email) IN ? AND role IN ? AND email_verified_at IS NOT NULL", emails, from).
+		Update("role", role)
+	if result.Error != nil {
+		log.Printf("failed to grant %s role: %v", role, result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("granted %s role to %d user(s)", role, result.RowsAffected)
+	}
+}
+
+func envEmails(key string) []string {
+	var emails []string
+	for _, email := range strings.Split(os.Getenv(key), ",") {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// EnvInt membaca angka positif dari environment, atau fallback jika kosong,
+// tidak valid, atau tidak lebih dari 0.
+func EnvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// EnvIntOrZero sama dengan EnvInt tetapi menerima 0, untuk pengaturan yang
+// boleh dimatikan dengan 0.
+func EnvIntOrZero(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value >= 0 {
+		return value
+	}
+	return fallback
+}
@@ -1,8 +1,49 @@
 package config
 
-import "github.com/Mikael88/go-mygram/models"
+import (
+	"log"
+	"time"
+
+	"github.com/Mikael88/go-mygram/hashtag"
+	"github.com/Mikael88/go-mygram/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 func RunMigration() {
-	DB.AutoMigrate(&models.User{}, &models.Photo{}, &models.Comment{}, &models.SocialMedia{})
+	DB.AutoMigrate(&models.User{}, &models.Photo{}, &models.Comment{}, &models.SocialMedia{}, &models.Session{}, &models.PasswordReset{}, &models.EmailVerification{}, &models.RecoveryCode{}, &models.PersonalAccessToken{}, &models.PhotoVariant{}, &models.Like{}, &models.Follow{}, &models.TimelineEntry{}, &models.AccessRequest{}, &models.Job{}, &models.Block{}, &models.Mute{}, &models.Tag{}, &models.TagLink{}, &models.TrendingTag{}, &models.Notification{}, &models.NotificationActor{}, &models.DataMigration{})
+
+	// Foto lama yang dibuat sebelum ada status dianggap terbit saat dibuat
+	DB.Model(&models.Photo{}).Where("status = ? AND published_at IS NULL", models.PhotoPublished).
+		UpdateColumn("published_at", gorm.Expr("created_at"))
+
+	// Caption dan komentar lama diurai sekali agar ikut muncul di tag dan trending
+	runOnce("hashtag_backfill", func() error {
+		return hashtag.Backfill(DB, 500)
+	})
+
+	BootstrapRoles()
 }
 
+// runOnce menjalankan migrasi data yang belum tercatat di data_migrations.
+// Migrasi yang gagal tidak dicatat sehingga dicoba lagi saat start berikutnya,
+// jadi fn harus aman dijalankan ulang.
+func runOnce(name string, fn func() error) {
+	var count int64
+	if err := DB.Model(&models.DataMigration{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		log.Printf("failed to check data migration %s: %v", name, err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	if err := fn(); err != nil {
+		log.Printf("data migration %s failed: %v", name, err)
+		return
+	}
+	migration := models.DataMigration{Name: name, RanAt: time.Now()}
+	if err := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&migration).Error; err != nil {
+		log.Printf("failed to record data migration %s: %v", name, err)
+	}
+}
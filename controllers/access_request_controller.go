@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/pagination"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PrivacyInput adalah struktur input untuk mengubah akun menjadi privat atau publik
+type PrivacyInput struct {
+	IsPrivate *bool `json:"is_private" binding:"required"`
+}
+
+// accessRequestPage adalah urutan yang diterima GetAccessRequests
+var accessRequestPage = pagination.Options[models.AccessRequest]{
+	Sorts: map[string]pagination.Sort[models.AccessRequest]{
+		"created_at": {Column: "created_at", Key: func(r models.AccessRequest) any { return r.CreatedAt }},
+	},
+	DefaultSort: "-created_at",
+	ID:          func(r models.AccessRequest) uint { return r.ID },
+}
+
+// UpdatePrivacy mengubah akun user yang sedang login menjadi privat atau publik
+func UpdatePrivacy(c *gin.Context) {
+	var input PrivacyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId := c.GetUint("userId")
+	if err := config.DB.Model(&models.User{}).Where("id = ?", userId).Update("is_private", *input.IsPrivate).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update privacy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"is_private": *input.IsPrivate}})
+}
+
+// RequestAccess meminta akses untuk melihat konten akun privat. Permintaan
+// yang pernah ditolak boleh diajukan ulang.
+func RequestAccess(c *gin.Context) {
+	userId := c.GetUint("userId")
+
+	account, ok := loadPathUser(c)
+	if !ok {
+		return
+	}
+
+	if account.ID == userId {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot request access to your own account"})
+		return
+	}
+	if !account.IsPrivate {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This account is public"})
+		return
+	}
+
+	relation, err := relationStatus(userId, account.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request access"})
+		return
+	}
+	if relation.blockedByMe {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unblock this user before requesting access"})
+		return
+	}
+
+	request := models.AccessRequest{RequesterID: userId, AccountID: account.ID, Status: models.AccessPending}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&request).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.AccessRequest{}).
+			Where("requester_id = ? AND account_id = ? AND status = ?", userId, account.ID, models.AccessRejected).
+			Updates(map[string]interface{}{"status": models.AccessPending, "responded_at": nil}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Preload("Requester").Where("requester_id = ? AND account_id = ?", userId, account.ID).First(&request).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request access"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": formatAccessRequest(request)})
+}
+
+// CancelAccessRequest membatalkan permintaan akses milik user yang sedang
+// login, termasuk melepas akses yang sudah disetujui
+func CancelAccessRequest(c *gin.Context) {
+	account, ok := loadPathUser(c)
+	if !ok {
+		return
+	}
+
+	result := config.DB.Where("requester_id = ? AND account_id = ?", c.GetUint("userId"), account.ID).Delete(&models.AccessRequest{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel access request"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Access request not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Access request cancelled successfully"})
+}
+
+// GetAccessRequests menampilkan permintaan akses yang masuk ke akun user yang
+// sedang login. Default hanya permintaan pending, gunakan ?status= untuk yang lain.
+func GetAccessRequests(c *gin.Context) {
+	page, err := pagination.Parse(c, accessRequestPage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := c.DefaultQuery("status", models.AccessPending)
+	if status != models.AccessPending && status != models.AccessApproved && status != models.AccessRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, approved or rejected"})
+		return
+	}
+
+	var requests []models.AccessRequest
+	query := config.DB.Preload("Requester").Where("account_id = ? AND status = ?", c.GetUint("userId"), status)
+	next, err := page.Find(query, &requests)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch access requests"})
+		return
+	}
+
+	formattedRequests := make([]gin.H, len(requests))
+	for i, request := range requests {
+		formattedRequests[i] = formatAccessRequest(request)
+	}
+
+	c.JSON(http.StatusOK, page.Response(formattedRequests, next))
+}
+
+// ApproveAccessRequest menyetujui permintaan akses sehingga requester bisa
+// melihat konten akun privat
+func ApproveAccessRequest(c *gin.Context) {
+	respondAccessRequest(c, models.AccessApproved)
+}
+
+// RejectAccessRequest menolak permintaan akses yang masih pending
+func RejectAccessRequest(c *gin.Context) {
+	respondAccessRequest(c, models.AccessRejected)
+}
+
+// RevokeAccessRequest menghapus permintaan akses, sekaligus mencabut akses
+// yang sudah disetujui
+func RevokeAccessRequest(c *gin.Context) {
+	request := c.MustGet("accessRequest").(*models.AccessRequest)
+
+	if err := config.DB.Delete(request).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke access"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Access revoked successfully"})
+}
+
+func respondAccessRequest(c *gin.Context, status string) {
+	// Permintaan sudah dimuat dan dicek pemilik akunnya oleh AuthorizeOwner
+	request := c.MustGet("accessRequest").(*models.AccessRequest)
+
+	if status == models.AccessRejected && request.Status != models.AccessPending {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only pending access requests can be rejected"})
+		return
+	}
+
+	if request.Status != status {
+		now := time.Now()
+		request.Status = status
+		request.RespondedAt = &now
+		if err := config.DB.Model(request).Updates(map[string]interface{}{"status": status, "responded_at": now}).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update access request"})
+			return
+		}
+	}
+
+	if err := config.DB.Where("id = ?", request.RequesterID).First(&request.Requester).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load requester"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": formatAccessRequest(*request)})
+}
+
+func formatAccessRequest(request models.AccessRequest) gin.H {
+	return gin.H{
+		"id": request.ID,
+		"requester": gin.H{
+			"id":       request.Requester.ID,
+			"username": request.Requester.Username,
+		},
+		"account_id":   request.AccountID,
+		"status":       request.Status,
+		"responded_at": request.RespondedAt,
+		"created_at":   request.CreatedAt,
+	}
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Mikael88/go-mygram/auth"
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateRoleInput adalah struktur input untuk mengganti role user
+type UpdateRoleInput struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// adminUserPage adalah urutan dan filter yang diterima AdminGetUsers
+var adminUserPage = pagination.Options[models.User]{
+	Sorts: map[string]pagination.Sort[models.User]{
+		"id":         {Column: "id", Key: func(u models.User) any { return u.ID }},
+		"created_at": {Column: "created_at", Key: func(u models.User) any { return u.CreatedAt }},
+		"username":   {Column: "username", Key: func(u models.User) any { return u.Username }},
+	},
+	DefaultSort:   "id",
+	ID:            func(u models.User) uint { return u.ID },
+	CreatedColumn: "created_at",
+}
+
+// AdminGetUsers menampilkan daftar user beserta role-nya
+func AdminGetUsers(c *gin.Context) {
+	page, err := pagination.Parse(c, adminUserPage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var users []models.User
+
+	query := config.DB
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	next, err := page.Find(query, &users)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	formattedUsers := make([]models.UserResponse, len(users))
+	for i, user := range users {
+		formattedUsers[i] = models.UserResponse{
+			Age:           user.Age,
+			Email:         user.Email,
+			ID:            user.ID,
+			Username:      user.Username,
+			Role:          user.Role,
+			EmailVerified: user.IsEmailVerified(),
+			IsPrivate:     user.IsPrivate,
+		}
+	}
+
+	c.JSON(http.StatusOK, page.Response(formattedUsers, next))
+}
+
+// AdminUpdateUserRole mengganti role user dan mencabut sesi lamanya
+// agar role baru langsung berlaku
+func AdminUpdateUserRole(c *gin.Context) {
+	var input UpdateRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !auth.ValidRole(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role: " + input.Role})
+		return
+	}
+
+	user, ok := adminTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Model(&user).Update("role", input.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	if err := revokeSessions("user_id = ?", user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke user's sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"id": user.ID, "username": user.Username, "role": input.Role}})
+}
+
+// AdminDeleteUser menghapus akun user lain beserta semua datanya
+func AdminDeleteUser(c *gin.Context) {
+	user, ok := adminTargetUser(c)
+	if !ok {
+		return
+	}
+
+	if err := deleteUserAccount(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User has been successfully deleted"})
+}
+
+// adminTargetUser memuat user dari path parameter. Admin tidak boleh mengubah
+// akunnya sendiri lewat endpoint admin agar tidak terkunci tanpa admin.
+func adminTargetUser(c *gin.Context) (models.User, bool) {
+	var user models.User
+	if err := config.DB.Where("id = ?", c.Param("userId")).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return user, false
+	}
+
+	if user.ID == c.GetUint("userId") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use the account endpoints to manage your own account"})
+		return user, false
+	}
+
+	return user, true
+}
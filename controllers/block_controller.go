@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/feed"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/pagination"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// blockPage adalah urutan yang diterima GetBlockedUsers
+var blockPage = pagination.Options[models.Block]{
+	Sorts: map[string]pagination.Sort[models.Block]{
+		"created_at": {Column: "created_at", Key: func(b models.Block) any { return b.CreatedAt }},
+	},
+	DefaultSort: "-created_at",
+	ID:          func(b models.Block) uint { return b.ID },
+}
+
+// mutePage adalah urutan yang diterima GetMutedUsers
+var mutePage = pagination.Options[models.Mute]{
+	Sorts: map[string]pagination.Sort[models.Mute]{
+		"created_at": {Column: "created_at", Key: func(m models.Mute) any { return m.CreatedAt }},
+	},
+	DefaultSort: "-created_at",
+	ID:          func(m models.Mute) uint { return m.ID },
+}
+
+// BlockUser memblokir user lain. Relasi follow dan permintaan akses di antara
+// keduanya ikut dihapus.
+func BlockUser(c *gin.Context) {
+	userId := c.GetUint("userId")
+
+	target, ok := findPathUser(c)
+	if !ok {
+		return
+	}
+
+	if target.ID == userId {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot block yourself"})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		block := models.Block{BlockerID: userId, BlockedID: target.ID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&block).Error; err != nil {
+			return err
+		}
+
+		var follows []models.Follow
+		err := tx.Where("follower_id = ? AND followee_id = ?", userId, target.ID).
+			Or("follower_id = ? AND followee_id = ?", target.ID, userId).
+			Find(&follows).Error
+		if err != nil {
+			return err
+		}
+		for _, follow := range follows {
+			if err := tx.Delete(&follow).Error; err != nil {
+				return err
+			}
+			if err := feed.Default.Unfollowed(tx, follow.FollowerID, follow.FolloweeID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("requester_id = ? AND account_id = ?", userId, target.ID).
+			Or("requester_id = ? AND account_id = ?", target.ID, userId).
+			Delete(&models.AccessRequest{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to block user"})
+		return
+	}
+
+	respondBlockStatus(c, target)
+}
+
+// UnblockUser membuka blokir user. Relasi follow yang sudah dihapus tidak dipulihkan.
+func UnblockUser(c *gin.Context) {
+	target, ok := findPathUser(c)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Where("blocker_id = ? AND blocked_id = ?", c.GetUint("userId"), target.ID).Delete(&models.Block{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unblock user"})
+		return
+	}
+
+	respondBlockStatus(c, target)
+}
+
+// MuteUser menyembunyikan konten user lain dari feed dan daftar komentar
+// tanpa memberi tahu user tersebut
+func MuteUser(c *gin.Context) {
+	userId := c.GetUint("userId")
+
+	target, ok := findPathUser(c)
+	if !ok {
+		return
+	}
+
+	if target.ID == userId {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot mute yourself"})
+		return
+	}
+
+	mute := models.Mute{MuterID: userId, MutedID: target.ID}
+	if err := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&mute).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mute user"})
+		return
+	}
+
+	respondBlockStatus(c, target)
+}
+
+// UnmuteUser menampilkan kembali konten user yang di-mute
+func UnmuteUser(c *gin.Context) {
+	target, ok := findPathUser(c)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Where("muter_id = ? AND muted_id = ?", c.GetUint("userId"), target.ID).Delete(&models.Mute{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmute user"})
+		return
+	}
+
+	respondBlockStatus(c, target)
+}
+
+// GetBlockedUsers menampilkan daftar user yang diblokir user yang sedang login
+func GetBlockedUsers(c *gin.Context) {
+	page, err := pagination.Parse(c, blockPage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var blocks []models.Block
+	next, err := page.Find(config.DB.Preload("Blocked").Where("blocker_id = ?", c.GetUint("userId")), &blocks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blocked users"})
+		return
+	}
+
+	formattedUsers := make([]gin.H, len(blocks))
+	for i, block := range blocks {
+		formattedUsers[i] = gin.H{
+			"user": gin.H{
+				"id":       block.Blocked.ID,
+				"username": block.Blocked.Username,
+			},
+			"blocked_at": block.CreatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, page.Response(formattedUsers, next))
+}
+
+// GetMutedUsers menampilkan daftar user yang di-mute user yang sedang login
+func GetMutedUsers(c *gin.Context) {
+	page, err := pagination.Parse(c, mutePage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var mutes []models.Mute
+	next, err := page.Find(config.DB.Preload("Muted").Where("muter_id = ?", c.GetUint("userId")), &mutes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch muted users"})
+		return
+	}
+
+	formattedUsers := make([]gin.H, len(mutes))
+	for i, mute := range mutes {
+		formattedUsers[i] = gin.H{
+			"user": gin.H{
+				"id":       mute.Muted.ID,
+				"username": mute.Muted.Username,
+			},
+			"muted_at": mute.CreatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, page.Response(formattedUsers, next))
+}
+
+func respondBlockStatus(c *gin.Context, target models.User) {
+	status, err := relationStatus(c.GetUint("userId"), target.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load block status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"user_id":       target.ID,
+		"blocked_by_me": status.blockedByMe,
+		"muted_by_me":   status.mutedByMe,
+	}})
+}
+
+// userRelation adalah status blokir dan mute antara viewer dan user lain
+type userRelation struct {
+	blockedByMe bool
+	blocksMe    bool
+	mutedByMe   bool
+}
+
+// relationStatus mengembalikan status blokir dua arah dan mute viewer terhadap user
+func relationStatus(viewerId, userId uint) (userRelation, error) {
+	var status userRelation
+
+	var blocks []models.Block
+	err := config.DB.Where("blocker_id = ? AND blocked_id = ?", viewerId, userId).
+		Or("blocker_id = ? AND blocked_id = ?", userId, viewerId).
+		Find(&blocks).Error
+	if err != nil {
+		return status, err
+	}
+	for _, block := range blocks {
+		if block.BlockerID == viewerId {
+			status.blockedByMe = true
+		} else {
+			status.blocksMe = true
+		}
+	}
+
+	var mutes int64
+	if err := config.DB.Model(&models.Mute{}).Where("muter_id = ? AND muted_id = ?", viewerId, userId).Count(&mutes).Error; err != nil {
+		return status, err
+	}
+	status.mutedByMe = mutes > 0
+
+	return status, nil
+}
@@ -1,19 +1,26 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/hashtag"
 	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/notifications"
+	"github.com/Mikael88/go-mygram/pagination"
+	"github.com/Mikael88/go-mygram/visibility"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // Create Comment validation
 type CreateCommentInput struct {
-	Message string `json:"message" binding:"required"`
-	PhotoID uint   `json:"photo_id" binding:"required"`
+	Message  string `json:"message" binding:"required"`
+	PhotoID  uint   `json:"photo_id" binding:"required"`
+	ParentID *uint  `json:"parent_id"`
 }
 func CreateComment(c *gin.Context) {
 	var input CreateCommentInput
@@ -37,15 +44,63 @@ func CreateComment(c *gin.Context) {
 		return
 	}
 
+	var photo models.Photo
+	if err := config.DB.Scopes(visibility.Photos(userId.(uint))).Select("id", "user_id").Where("id = ?", input.PhotoID).First(&photo).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Photo not found"})
+		return
+	}
+
 	// Buat objek komentar
 	comment := models.Comment{
-		Message: input.Message,
-		PhotoID: input.PhotoID,
-		UserID:  userId.(uint), // Konversi userId menjadi uint
+		Message:  input.Message,
+		PhotoID:  input.PhotoID,
+		ParentID: input.ParentID,
+		UserID:   userId.(uint), // Konversi userId menjadi uint
+	}
+
+	// Balasan harus berada di foto yang sama dan tidak melebihi kedalaman maksimum
+	if input.ParentID != nil {
+		var parent models.Comment
+		if err := config.DB.Scopes(visibility.Comments(userId.(uint))).Where("id = ?", *input.ParentID).First(&parent).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Parent comment not found"})
+			return
+		}
+		if parent.PhotoID != input.PhotoID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parent comment belongs to a different photo"})
+			return
+		}
+		if maxDepth := commentMaxDepth(); parent.Depth+1 > maxDepth {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Replies cannot be nested deeper than %d levels", maxDepth)})
+			return
+		}
+		comment.Depth = parent.Depth + 1
 	}
 
-	// Simpan komentar ke database
-	if err := config.DB.Create(&comment).Error; err != nil {
+	// Simpan komentar ke database dan perbarui jumlah balasan induknya
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		if err := hashtag.Sync(tx, models.TagTargetComment, comment.ID, comment.UserID, comment.Message); err != nil {
+			return err
+		}
+		err := notifications.Notify(tx, notifications.Event{
+			UserID:     photo.UserID,
+			Type:       models.NotificationCommentCreated,
+			ActorID:    comment.UserID,
+			TargetType: models.NotificationTargetPhoto,
+			TargetID:   photo.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if comment.ParentID == nil {
+			return nil
+		}
+		return tx.Model(&models.Comment{}).Where("id = ?", *comment.ParentID).
+			UpdateColumn("reply_count", gorm.Expr("reply_count + 1")).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -53,122 +108,236 @@ func CreateComment(c *gin.Context) {
 	response := map[string]interface{}{
 		"id":        comment.ID,
 		"message":   comment.Message,
+		"tags":      hashtag.Parse(comment.Message),
 		"photo_id":  comment.PhotoID,
+		"parent_id": comment.ParentID,
+		"depth":     comment.Depth,
+		"like_count":  0,
+		"liked_by_me": false,
 		"user_id":   comment.UserID,
 		"created_at": comment.CreatedAt.Format(time.RFC3339), // Format date-time
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"data": response})
 }
+// commentPage adalah urutan dan filter yang diterima GetComments
+var commentPage = pagination.Options[models.Comment]{
+    Sorts: map[string]pagination.Sort[models.Comment]{
+        "created_at": {Column: "created_at", Key: func(c models.Comment) any { return c.CreatedAt }},
+        "updated_at": {Column: "updated_at", Key: func(c models.Comment) any { return c.UpdatedAt }},
+        "id":         {Column: "id", Key: func(c models.Comment) any { return c.ID }},
+    },
+    DefaultSort:   "-created_at",
+    ID:            func(c models.Comment) uint { return c.ID },
+    Filters:       map[string]string{"user_id": "user_id", "photo_id": "photo_id"},
+    CreatedColumn: "created_at",
+}
 // GetComments mengambil daftar komentar
 func GetComments(c *gin.Context) {
+    page, err := pagination.Parse(c, commentPage)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
     var comments []models.Comment
 
-    // Ambil semua komentar dari database
-    if err := config.DB.Preload("User").Preload("Photo.User").Find(&comments).Error; err != nil {
+    // Ambil satu halaman komentar dari database
+    // Komentar pada foto unlisted tidak ikut muncul di daftar global
+    userId := c.GetUint("userId")
+    listedPhotos := config.DB.Model(&models.Photo{}).Select("id").Scopes(visibility.ListedPhotos(userId))
+    query := config.DB.Scopes(visibility.Comments(userId), visibility.Unmuted(userId, "comments.user_id")).
+        Where("comments.photo_id IN (?)", listedPhotos).
+        Preload("User").Preload("Photo.User")
+    next, err := page.Find(query, &comments)
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
 
-    // Jika tidak ada komentar ditemukan, kembalikan respons kosong
-    if len(comments) == 0 {
-        c.JSON(http.StatusOK, gin.H{"message": "No comments found"})
+    liked, err := likedCommentIds(c, comments)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
 
     // Transformasi data komentar ke format yang diinginkan
     formattedComments := make([]map[string]interface{}, len(comments))
     for i, comment := range comments {
-        formattedComment := map[string]interface{}{
-            "id":         comment.ID,
-            "message":    comment.Message,
-            "photo_id":   comment.PhotoID,
-            "user_id":    comment.UserID,
-            "updated_at": comment.UpdatedAt,
-            "created_at": comment.CreatedAt,
-            "User": map[string]interface{}{
-                "id":       comment.User.ID,
-                "email":    comment.User.Email,
-                "username": comment.User.Username,
-            },
-            "Photo": map[string]interface{}{
-                "id":        comment.Photo.ID,
-                "title":     comment.Photo.Title,
-                "caption":   comment.Photo.Caption,
-                "photo_url": comment.Photo.PhotoURL,
-                "user_id":   comment.Photo.User.ID,
-            },
-        }
-        formattedComments[i] = formattedComment
+        formattedComments[i] = formatComment(comment, liked[comment.ID])
     }
 
     // Kembalikan daftar komentar dalam format yang diinginkan
-    c.JSON(http.StatusOK, formattedComments)
+    c.JSON(http.StatusOK, page.Response(formattedComments, next))
 }
-// UpdateComment mengelola proses pembaruan komentar.
-func UpdateComment(c *gin.Context) {
-    commentID := c.Param("commentId")
-    var comment models.Comment
-    if err := config.DB.Where("id = ?", commentID).Preload("Photo").Preload("Photo.User").First(&comment).Error; err != nil {
+// formatComment menyusun komentar beserta user dan fotonya untuk respons daftar
+func formatComment(comment models.Comment, likedByMe bool) map[string]interface{} {
+    return map[string]interface{}{
+        "id":         comment.ID,
+        "message":    comment.Message,
+        "tags":       hashtag.Parse(comment.Message),
+        "photo_id":   comment.PhotoID,
+        "parent_id":  comment.ParentID,
+        "depth":      comment.Depth,
+        "reply_count": comment.ReplyCount,
+        "like_count": comment.LikeCount,
+        "liked_by_me": likedByMe,
+        "user_id":    comment.UserID,
+        "updated_at": comment.UpdatedAt,
+        "created_at": comment.CreatedAt,
+        "User": map[string]interface{}{
+            "id":       comment.User.ID,
+            "email":    comment.User.Email,
+            "username": comment.User.Username,
+        },
+        "Photo": map[string]interface{}{
+            "id":        comment.Photo.ID,
+            "title":     comment.Photo.Title,
+            "caption":   comment.Photo.Caption,
+            "photo_url": photoURL(comment.Photo),
+            "user_id":   comment.Photo.User.ID,
+        },
+    }
+}
+// GetPhotoComments mengambil komentar utama sebuah foto. Balasan dimuat
+// terpisah melalui GetCommentReplies.
+func GetPhotoComments(c *gin.Context) {
+    page, err := pagination.Parse(c, threadPage)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    var photo models.Photo
+    userId := c.GetUint("userId")
+    if err := config.DB.Scopes(visibility.Photos(userId)).Select("id").Where("id = ?", c.Param("photoId")).First(&photo).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Photo not found"})
+        return
+    }
+
+    var comments []models.Comment
+    query := config.DB.Scopes(visibility.Comments(userId), visibility.Unmuted(userId, "comments.user_id")).
+        Preload("User").Where("photo_id = ? AND parent_id IS NULL", photo.ID)
+    next, err := page.Find(query, &comments)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+        return
+    }
+
+    liked, err := likedCommentIds(c, comments)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+        return
+    }
+
+    formattedComments := make([]gin.H, len(comments))
+    for i, comment := range comments {
+        formattedComments[i] = formatThreadComment(comment, liked[comment.ID])
+    }
+
+    c.JSON(http.StatusOK, page.Response(formattedComments, next))
+}
+// GetCommentReplies mengambil balasan langsung dari sebuah komentar
+func GetCommentReplies(c *gin.Context) {
+    page, err := pagination.Parse(c, threadPage)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    var parent models.Comment
+    userId := c.GetUint("userId")
+    if err := config.DB.Scopes(visibility.Comments(userId)).Select("id").Where("id = ?", c.Param("commentId")).First(&parent).Error; err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
         return
     }
 
-    // Periksa apakah pengguna yang meminta pembaruan adalah pemilik komentar
-    userId, exists := c.Get("userId")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+    var replies []models.Comment
+    query := config.DB.Scopes(visibility.Comments(userId), visibility.Unmuted(userId, "comments.user_id")).
+        Preload("User").Where("parent_id = ?", parent.ID)
+    next, err := page.Find(query, &replies)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch replies"})
         return
     }
-    if comment.UserID != userId.(uint) {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+
+    liked, err := likedCommentIds(c, replies)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch replies"})
         return
     }
 
+    formattedReplies := make([]gin.H, len(replies))
+    for i, reply := range replies {
+        formattedReplies[i] = formatThreadComment(reply, liked[reply.ID])
+    }
+
+    c.JSON(http.StatusOK, page.Response(formattedReplies, next))
+}
+// UpdateComment mengelola proses pembaruan komentar.
+func UpdateComment(c *gin.Context) {
+    // Komentar sudah dimuat dan dicek kepemilikannya oleh AuthorizeOwner
+    comment := c.MustGet("comment").(*models.Comment)
+
     var updateComment models.Comment
     if err := c.ShouldBindJSON(&updateComment); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
+    // Pemilik foto hanya diberi tahu jika isi komentar benar-benar berubah
+    messageChanged := comment.Message != updateComment.Message
     comment.Message = updateComment.Message
-    if err := config.DB.Save(&comment).Error; err != nil {
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Save(comment).Error; err != nil {
+            return err
+        }
+        if err := hashtag.Sync(tx, models.TagTargetComment, comment.ID, comment.UserID, comment.Message); err != nil {
+            return err
+        }
+        if !messageChanged {
+            return nil
+        }
+        var photo models.Photo
+        if err := tx.Select("id", "user_id").Where("id = ?", comment.PhotoID).Take(&photo).Error; err != nil {
+            return err
+        }
+        return notifications.Notify(tx, notifications.Event{
+            UserID:     photo.UserID,
+            Type:       models.NotificationCommentEdited,
+            ActorID:    comment.UserID,
+            TargetType: models.NotificationTargetPhoto,
+            TargetID:   photo.ID,
+        })
+    })
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update comment"})
         return
     }
 
+    if err := config.DB.Preload("User").Where("id = ?", comment.PhotoID).First(&comment.Photo).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load photo"})
+        return
+    }
+
     c.JSON(http.StatusOK, gin.H{
         "id":         comment.Photo.ID,
         "title":      comment.Photo.Title,
         "caption":    comment.Photo.Caption,
-        "photo_url":  comment.Photo.PhotoURL,
+        "photo_url":  photoURL(comment.Photo),
         "user_id":    comment.Photo.User.ID,
         "updated_at": comment.Photo.UpdatedAt,
     })
 }
 // DeleteComment mengelola proses penghapusan komentar.
 func DeleteComment(c *gin.Context) {
-	commentID := c.Param("commentId")
-
-	var comment models.Comment
-	if err := config.DB.Where("id = ?", commentID).First(&comment).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
-		return
-	}
-
-	// Periksa apakah pengguna yang meminta penghapusan adalah pemilik komentar
-	userId, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	if comment.UserID != userId.(uint) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
+	comment := c.MustGet("comment").(*models.Comment)
 
-	if err := config.DB.Delete(&comment).Error; err != nil {
+	// Seluruh balasan di bawah komentar ikut dihapus
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return deleteCommentThreads(tx, []models.Comment{*comment})
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
 		return
 	}
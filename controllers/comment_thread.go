@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/hashtag"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/notifications"
+	"github.com/Mikael88/go-mygram/pagination"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// defaultCommentMaxDepth adalah kedalaman balasan maksimum jika
+// COMMENT_MAX_DEPTH tidak diatur. Komentar utama memiliki depth 0.
+const defaultCommentMaxDepth = 3
+
+// threadPage adalah urutan yang diterima endpoint komentar per foto dan balasan
+var threadPage = pagination.Options[models.Comment]{
+	Sorts: map[string]pagination.Sort[models.Comment]{
+		"created_at":  {Column: "created_at", Key: func(c models.Comment) any { return c.CreatedAt }},
+		"reply_count": {Column: "reply_count", Key: func(c models.Comment) any { return c.ReplyCount }},
+		"id":          {Column: "id", Key: func(c models.Comment) any { return c.ID }},
+	},
+	DefaultSort:   "created_at",
+	ID:            func(c models.Comment) uint { return c.ID },
+	Filters:       map[string]string{"user_id": "user_id"},
+	CreatedColumn: "created_at",
+}
+
+func commentMaxDepth() int {
+	return config.EnvIntOrZero("COMMENT_MAX_DEPTH", defaultCommentMaxDepth)
+}
+
+// formatThreadComment menyusun komentar untuk daftar thread. Foto tidak
+// disertakan karena semua komentar dalam satu thread berada di foto yang sama.
+func formatThreadComment(comment models.Comment, likedByMe bool) gin.H {
+	return gin.H{
+		"id":          comment.ID,
+		"message":     comment.Message,
+		"tags":        hashtag.Parse(comment.Message),
+		"photo_id":    comment.PhotoID,
+		"parent_id":   comment.ParentID,
+		"depth":       comment.Depth,
+		"reply_count": comment.ReplyCount,
+		"like_count":  comment.LikeCount,
+		"liked_by_me": likedByMe,
+		"user_id":     comment.UserID,
+		"created_at":  comment.CreatedAt,
+		"updated_at":  comment.UpdatedAt,
+		"user": gin.H{
+			"id":       comment.User.ID,
+			"username": comment.User.Username,
+		},
+	}
+}
+
+// likedCommentIds mengembalikan komentar mana saja yang disukai user yang sedang login
+func likedCommentIds(c *gin.Context, comments []models.Comment) (map[uint]bool, error) {
+	ids := make([]uint, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+	return likedTargets(c.GetUint("userId"), models.LikeTargetComment, ids)
+}
+
+// deleteCommentThreads menghapus komentar beserta seluruh balasannya, lalu
+// mengurangi reply_count induk yang tidak ikut terhapus.
+func deleteCommentThreads(tx *gorm.DB, roots []models.Comment) error {
+	if len(roots) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(roots))
+	for _, root := range roots {
+		ids = append(ids, root.ID)
+	}
+
+	// Kedalaman thread dibatasi, jadi jumlah iterasi juga terbatas
+	deleted := map[uint]bool{}
+	for frontier := ids; len(frontier) > 0; {
+		for _, id := range frontier {
+			deleted[id] = true
+		}
+		var children []uint
+		if err := tx.Model(&models.Comment{}).Where("parent_id IN ?", frontier).Pluck("id", &children).Error; err != nil {
+			return err
+		}
+		frontier = children
+	}
+
+	for _, root := range roots {
+		if root.ParentID == nil || deleted[*root.ParentID] {
+			continue
+		}
+		err := tx.Model(&models.Comment{}).Where("id = ?", *root.ParentID).
+			UpdateColumn("reply_count", gorm.Expr("reply_count - 1")).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	all := make([]uint, 0, len(deleted))
+	for id := range deleted {
+		all = append(all, id)
+	}
+	if err := deleteTargetLikes(tx, models.LikeTargetComment, all); err != nil {
+		return err
+	}
+	if err := hashtag.Unlink(tx, models.TagTargetComment, all); err != nil {
+		return err
+	}
+	if err := models.RemoveSearchDocuments(tx, models.SearchKindComment, all); err != nil {
+		return err
+	}
+
+	var authors []models.Comment
+	if err := tx.Model(&models.Comment{}).Distinct("photo_id", "user_id").Where("id IN ?", all).Find(&authors).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("id IN ?", all).Delete(&models.Comment{}).Error; err != nil {
+		return err
+	}
+
+	// Penulis yang tidak lagi punya komentar di foto dikeluarkan dari
+	// notifikasi komentar pemilik foto
+	for _, author := range authors {
+		var remaining int64
+		err := tx.Model(&models.Comment{}).Where("photo_id = ? AND user_id = ?", author.PhotoID, author.UserID).Count(&remaining).Error
+		if err != nil {
+			return err
+		}
+		if remaining > 0 {
+			continue
+		}
+		if err := notifications.RemoveActor(tx, models.NotificationTargetPhoto, author.PhotoID, author.UserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/feed"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/pagination"
+	"github.com/Mikael88/go-mygram/visibility"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetFeed menampilkan foto dari akun yang diikuti user beserta fotonya sendiri,
+// yang terakhir terbit lebih dulu. Kolom urutan mengikuti strategi feed yang aktif.
+func GetFeed(c *gin.Context) {
+	strategy := feed.Default
+
+	page, err := pagination.Parse(c, pagination.Options[models.Photo]{
+		Sorts: map[string]pagination.Sort[models.Photo]{
+			"published_at": {Column: strategy.SortColumn(), Key: func(p models.Photo) any { return p.PublishedTime() }},
+		},
+		DefaultSort: "-published_at",
+		IDColumn:    strategy.IDColumn(),
+		ID:          func(p models.Photo) uint { return p.ID },
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var photos []models.Photo
+	userId := c.GetUint("userId")
+	query := config.DB.Scopes(visibility.ListedPhotos(userId), visibility.Unmuted(userId, "photos.user_id")).
+		Preload("User").Preload("Variants")
+	query = strategy.Query(query, userId)
+	next, err := page.Find(query, &photos)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed"})
+		return
+	}
+
+	formattedPhotos, err := formatPhotos(c, photos)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page.Response(formattedPhotos, next))
+}
@@ -0,0 +1,305 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/feed"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/pagination"
+	"github.com/Mikael88/go-mygram/visibility"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// followPage adalah urutan yang diterima endpoint followers dan following
+var followPage = pagination.Options[models.Follow]{
+	Sorts: map[string]pagination.Sort[models.Follow]{
+		"created_at": {Column: "created_at", Key: func(f models.Follow) any { return f.CreatedAt }},
+	},
+	DefaultSort: "-created_at",
+	ID:          func(f models.Follow) uint { return f.ID },
+}
+
+// FollowUser mengikuti user lain. Mengikuti user yang sudah diikuti tidak mengubah apa pun.
+func FollowUser(c *gin.Context) {
+	userId := c.GetUint("userId")
+
+	target, ok := loadPathUser(c)
+	if !ok {
+		return
+	}
+
+	if target.ID == userId {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot follow yourself"})
+		return
+	}
+
+	relation, err := relationStatus(userId, target.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow user"})
+		return
+	}
+	if relation.blockedByMe {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unblock this user before following them"})
+		return
+	}
+
+	// Feed follower diperbarui hanya jika relasi follow benar-benar baru
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		follow := models.Follow{FollowerID: userId, FolloweeID: target.ID}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return feed.Default.Followed(tx, userId, target.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow user"})
+		return
+	}
+
+	respondFollowStatus(c, target)
+}
+
+// UnfollowUser berhenti mengikuti user
+func UnfollowUser(c *gin.Context) {
+	userId := c.GetUint("userId")
+
+	target, ok := loadPathUser(c)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("follower_id = ? AND followee_id = ?", userId, target.ID).Delete(&models.Follow{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return feed.Default.Unfollowed(tx, userId, target.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow user"})
+		return
+	}
+
+	respondFollowStatus(c, target)
+}
+
+// GetFollowers menampilkan daftar user yang mengikuti user pada path
+func GetFollowers(c *gin.Context) {
+	listFollows(c, "followee_id", "follower_id", "Follower", func(f models.Follow) models.User { return f.Follower })
+}
+
+// GetFollowing menampilkan daftar user yang diikuti user pada path
+func GetFollowing(c *gin.Context) {
+	listFollows(c, "follower_id", "followee_id", "Followee", func(f models.Follow) models.User { return f.Followee })
+}
+
+// GetUserProfile menampilkan profil publik user beserta jumlah follower dan
+// hubungan follow dengan user yang sedang login
+func GetUserProfile(c *gin.Context) {
+	userId := c.GetUint("userId")
+
+	user, ok := loadPathUser(c)
+	if !ok {
+		return
+	}
+
+	profile := models.ProfileResponse{
+		UserResponse: models.UserResponse{
+			ID:            user.ID,
+			Username:      user.Username,
+			Role:          user.Role,
+			EmailVerified: user.IsEmailVerified(),
+			IsPrivate:     user.IsPrivate,
+		},
+	}
+
+	// Data pribadi hanya ditampilkan ke pemilik profil
+	if user.ID == userId {
+		profile.Email = user.Email
+		profile.Age = user.Age
+	}
+
+	// Jumlah foto hanya menghitung foto yang muncul di daftar untuk viewer
+	counts := []struct {
+		query *gorm.DB
+		where string
+		dest  *int64
+	}{
+		{config.DB.Model(&models.Photo{}).Scopes(visibility.ListedPhotos(userId)), "photos.user_id = ?", &profile.PhotoCount},
+		{config.DB.Model(&models.Follow{}), "followee_id = ?", &profile.FollowerCount},
+		{config.DB.Model(&models.Follow{}), "follower_id = ?", &profile.FollowingCount},
+	}
+	for _, count := range counts {
+		if err := count.query.Where(count.where, user.ID).Count(count.dest).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load profile"})
+			return
+		}
+	}
+
+	followedByMe, followsMe, err := followStatus(userId, []uint{user.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load profile"})
+		return
+	}
+	profile.FollowedByMe = followedByMe[user.ID]
+	profile.FollowsMe = followsMe[user.ID]
+	profile.Mutual = profile.FollowedByMe && profile.FollowsMe
+
+	if profile.CanView, err = visibility.CanViewUser(config.DB, userId, user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load profile"})
+		return
+	}
+	relation, err := relationStatus(userId, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load profile"})
+		return
+	}
+	profile.BlockedByMe = relation.blockedByMe
+	profile.MutedByMe = relation.mutedByMe
+	if user.IsPrivate && user.ID != userId {
+		var request models.AccessRequest
+		if err := config.DB.Where("requester_id = ? AND account_id = ?", userId, user.ID).Limit(1).Find(&request).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load profile"})
+			return
+		}
+		profile.AccessStatus = request.Status
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": profile})
+}
+
+// loadPathUser memuat user dari path parameter userId. User yang memblokir
+// viewer dianggap tidak ada.
+func loadPathUser(c *gin.Context) (models.User, bool) {
+	user, ok := findPathUser(c)
+	if !ok {
+		return user, false
+	}
+
+	var blocks int64
+	err := config.DB.Model(&models.Block{}).Where("blocker_id = ? AND blocked_id = ?", user.ID, c.GetUint("userId")).Count(&blocks).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		return user, false
+	}
+	if blocks > 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return user, false
+	}
+	return user, true
+}
+
+// findPathUser memuat user dari path parameter userId tanpa memeriksa blokir
+func findPathUser(c *gin.Context) (models.User, bool) {
+	var user models.User
+	err := config.DB.Where("id = ?", c.Param("userId")).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return user, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		return user, false
+	}
+	return user, true
+}
+
+func respondFollowStatus(c *gin.Context, target models.User) {
+	followedByMe, followsMe, err := followStatus(c.GetUint("userId"), []uint{target.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load follow status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"user_id":        target.ID,
+		"followed_by_me": followedByMe[target.ID],
+		"follows_me":     followsMe[target.ID],
+		"mutual":         followedByMe[target.ID] && followsMe[target.ID],
+	}})
+}
+
+// listFollows menampilkan satu halaman relasi follow user pada path. column
+// adalah kolom yang berisi user tersebut, sedangkan otherColumn, preload, dan
+// other memilih user di sisi lain relasi.
+func listFollows(c *gin.Context, column, otherColumn, preload string, other func(models.Follow) models.User) {
+	page, err := pagination.Parse(c, followPage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := loadPathUser(c)
+	if !ok {
+		return
+	}
+
+	var follows []models.Follow
+	query := config.DB.Scopes(visibility.Users(c.GetUint("userId"), otherColumn)).Preload(preload).Where(column+" = ?", user.ID)
+	next, err := page.Find(query, &follows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	ids := make([]uint, len(follows))
+	for i, follow := range follows {
+		ids[i] = other(follow).ID
+	}
+	followedByMe, followsMe, err := followStatus(c.GetUint("userId"), ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	formattedUsers := make([]gin.H, len(follows))
+	for i, follow := range follows {
+		u := other(follow)
+		formattedUsers[i] = gin.H{
+			"user": gin.H{
+				"id":       u.ID,
+				"username": u.Username,
+			},
+			"followed_at":    follow.CreatedAt,
+			"followed_by_me": followedByMe[u.ID],
+			"follows_me":     followsMe[u.ID],
+			"mutual":         followedByMe[u.ID] && followsMe[u.ID],
+		}
+	}
+
+	c.JSON(http.StatusOK, page.Response(formattedUsers, next))
+}
+
+// followStatus mengembalikan user mana saja yang diikuti viewer dan user mana
+// saja yang mengikuti viewer, dengan satu query untuk seluruh daftar
+func followStatus(viewerId uint, ids []uint) (followedByMe, followsMe map[uint]bool, err error) {
+	followedByMe = map[uint]bool{}
+	followsMe = map[uint]bool{}
+	if len(ids) == 0 {
+		return followedByMe, followsMe, nil
+	}
+
+	var follows []models.Follow
+	err = config.DB.Where("follower_id = ? AND followee_id IN ?", viewerId, ids).
+		Or("followee_id = ? AND follower_id IN ?", viewerId, ids).
+		Find(&follows).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, follow := range follows {
+		if follow.FollowerID == viewerId {
+			followedByMe[follow.FolloweeID] = true
+		}
+		if follow.FolloweeID == viewerId {
+			followsMe[follow.FollowerID] = true
+		}
+	}
+	return followedByMe, followsMe, nil
+}
@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Mikael88/go-mygram/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJWKS mempublikasikan public key agar layanan lain bisa memverifikasi token MyGram
+func GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": auth.Keys.JWKS()})
+}
@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/pagination"
+	"github.com/Mikael88/go-mygram/visibility"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// likeTarget menjelaskan resource yang bisa disukai beserta tabel tempat
+// jumlah like-nya disimpan
+type likeTarget struct {
+	targetType string
+	table      string
+	param      string
+	name       string
+	// scope membatasi target ke yang boleh dilihat user
+	scope func(viewerId uint) visibility.Scope
+}
+
+var (
+	photoLikeTarget   = likeTarget{targetType: models.LikeTargetPhoto, table: "photos", param: "photoId", name: "Photo", scope: visibility.Photos}
+	commentLikeTarget = likeTarget{targetType: models.LikeTargetComment, table: "comments", param: "commentId", name: "Comment", scope: visibility.Comments}
+)
+
+// likePage adalah urutan yang diterima endpoint daftar like
+var likePage = pagination.Options[models.Like]{
+	Sorts: map[string]pagination.Sort[models.Like]{
+		"created_at": {Column: "created_at", Key: func(l models.Like) any { return l.CreatedAt }},
+	},
+	DefaultSort: "-created_at",
+	ID:          func(l models.Like) uint { return l.ID },
+}
+
+// LikePhoto menyukai foto. Menyukai foto yang sudah disukai tidak mengubah apa pun.
+func LikePhoto(c *gin.Context) {
+	setLike(c, photoLikeTarget, true)
+}
+
+// UnlikePhoto membatalkan like pada foto
+func UnlikePhoto(c *gin.Context) {
+	setLike(c, photoLikeTarget, false)
+}
+
+// GetPhotoLikes menampilkan daftar user yang menyukai foto
+func GetPhotoLikes(c *gin.Context) {
+	getLikes(c, photoLikeTarget)
+}
+
+// LikeComment menyukai komentar. Menyukai komentar yang sudah disukai tidak mengubah apa pun.
+func LikeComment(c *gin.Context) {
+	setLike(c, commentLikeTarget, true)
+}
+
+// UnlikeComment membatalkan like pada komentar
+func UnlikeComment(c *gin.Context) {
+	setLike(c, commentLikeTarget, false)
+}
+
+// GetCommentLikes menampilkan daftar user yang menyukai komentar
+func GetCommentLikes(c *gin.Context) {
+	getLikes(c, commentLikeTarget)
+}
+
+// loadLikeTarget memastikan target dari path parameter ada dan boleh dilihat
+// user, lalu mengembalikan ID-nya
+func loadLikeTarget(c *gin.Context, target likeTarget) (uint, bool) {
+	var row struct{ ID uint }
+	err := config.DB.Table(target.table).Scopes(target.scope(c.GetUint("userId"))).
+		Select("id").Where("id = ?", c.Param(target.param)).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": target.name + " not found"})
+		return 0, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load " + target.targetType})
+		return 0, false
+	}
+	return row.ID, true
+}
+
+// setLike menambah atau menghapus like user dan memperbarui jumlah like target
+// dalam satu transaksi. Permintaan yang diulang bersifat idempoten.
+func setLike(c *gin.Context, target likeTarget, liked bool) {
+	userId := c.GetUint("userId")
+
+	targetId, ok := loadLikeTarget(c, target)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var result *gorm.DB
+		delta := 1
+		if liked {
+			like := models.Like{TargetType: target.targetType, TargetID: targetId, UserID: userId}
+			result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&like)
+		} else {
+			result = tx.Where("target_type = ? AND target_id = ? AND user_id = ?", target.targetType, targetId, userId).
+				Delete(&models.Like{})
+			delta = -1
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// Like sudah dalam keadaan yang diminta, jumlahnya tidak berubah
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return tx.Table(target.table).Where("id = ?", targetId).
+			UpdateColumn("like_count", gorm.Expr("like_count + ?", delta)).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update like"})
+		return
+	}
+
+	var likeCount int
+	if err := config.DB.Table(target.table).Select("like_count").Where("id = ?", targetId).Scan(&likeCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update like"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"target_type": target.targetType,
+		"target_id":   targetId,
+		"like_count":  likeCount,
+		"liked_by_me": liked,
+	}})
+}
+
+func getLikes(c *gin.Context, target likeTarget) {
+	page, err := pagination.Parse(c, likePage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	targetId, ok := loadLikeTarget(c, target)
+	if !ok {
+		return
+	}
+
+	var likes []models.Like
+	query := config.DB.Scopes(visibility.Users(c.GetUint("userId"), "user_id")).Preload("User").
+		Where("target_type = ? AND target_id = ?", target.targetType, targetId)
+	next, err := page.Find(query, &likes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch likes"})
+		return
+	}
+
+	formattedLikes := make([]gin.H, len(likes))
+	for i, like := range likes {
+		formattedLikes[i] = gin.H{
+			"user": gin.H{
+				"id":       like.User.ID,
+				"username": like.User.Username,
+			},
+			"created_at": like.CreatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, page.Response(formattedLikes, next))
+}
+
+// likedTargets mengembalikan ID target yang sudah disukai user, dipakai untuk
+// mengisi liked_by_me pada daftar foto dan komentar dengan satu query.
+func likedTargets(userId uint, targetType string, ids []uint) (map[uint]bool, error) {
+	liked := map[uint]bool{}
+	if len(ids) == 0 {
+		return liked, nil
+	}
+
+	var likedIds []uint
+	err := config.DB.Model(&models.Like{}).
+		Where("target_type = ? AND target_id IN ? AND user_id = ?", targetType, ids, userId).
+		Pluck("target_id", &likedIds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range likedIds {
+		liked[id] = true
+	}
+	return liked, nil
+}
+
+// isLikedBy mengembalikan true jika user menyukai satu target
+func isLikedBy(userId uint, targetType string, targetId uint) bool {
+	liked, err := likedTargets(userId, targetType, []uint{targetId})
+	return err == nil && liked[targetId]
+}
+
+// deleteTargetLikes menghapus semua like pada target yang akan dihapus.
+// ids boleh berupa slice ID atau subquery.
+func deleteTargetLikes(tx *gorm.DB, targetType string, ids interface{}) error {
+	return tx.Where("target_type = ? AND target_id IN (?)", targetType, ids).Delete(&models.Like{}).Error
+}
+
+// removeUserLikes membatalkan semua like milik user dan mengurangi jumlah like
+// target yang disukainya
+func removeUserLikes(tx *gorm.DB, userId uint) error {
+	for _, target := range []likeTarget{photoLikeTarget, commentLikeTarget} {
+		likedIds := tx.Model(&models.Like{}).Select("target_id").
+			Where("target_type = ? AND user_id = ?", target.targetType, userId)
+		err := tx.Table(target.table).Where("id IN (?)", likedIds).
+			UpdateColumn("like_count", gorm.Expr("like_count - 1")).Error
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Where("user_id = ?", userId).Delete(&models.Like{}).Error
+}
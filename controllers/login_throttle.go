@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Mikael88/go-mygram/throttle"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loginAttempt adalah pasangan key throttling untuk satu percobaan login
+type loginAttempt struct {
+	accountKey string
+	ipKey      string
+}
+
+func newLoginAttempt(c *gin.Context, account string) loginAttempt {
+	return loginAttempt{
+		accountKey: "login:account:" + strings.ToLower(strings.TrimSpace(account)),
+		ipKey:      "login:ip:" + c.ClientIP(),
+	}
+}
+
+// allowed menulis respons 429 dengan header Retry-After jika akun atau IP sedang diblokir
+func (a loginAttempt) allowed(c *gin.Context) bool {
+	wait := maxDuration(
+		checkLimiter(throttle.AccountLimiter, a.accountKey),
+		checkLimiter(throttle.IPLimiter, a.ipKey),
+	)
+	if wait <= 0 {
+		return true
+	}
+
+	writeTooManyAttempts(c, wait)
+	return false
+}
+
+// fail mencatat kegagalan untuk akun dan IP, lalu mengembalikan waktu tunggu berikutnya
+func (a loginAttempt) fail() time.Duration {
+	return maxDuration(
+		failLimiter(throttle.AccountLimiter, a.accountKey),
+		failLimiter(throttle.IPLimiter, a.ipKey),
+	)
+}
+
+// succeed menghapus riwayat kegagalan akun. Riwayat IP tetap disimpan agar
+// satu akun valid tidak bisa dipakai untuk mereset penghitung IP.
+func (a loginAttempt) succeed() {
+	if err := throttle.AccountLimiter.Reset(a.accountKey); err != nil {
+		log.Printf("failed to reset login throttle: %v", err)
+	}
+}
+
+// rejectCredentials menulis respons 401 dan mencatat kegagalan login
+func (a loginAttempt) rejectCredentials(c *gin.Context, message string) {
+	if wait := a.fail(); wait > 0 {
+		c.Header("Retry-After", retryAfterSeconds(wait))
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+}
+
+func checkLimiter(limiter *throttle.Limiter, key string) time.Duration {
+	wait, err := limiter.Check(key)
+	if err != nil {
+		log.Printf("failed to check login throttle: %v", err)
+	}
+	return wait
+}
+
+func failLimiter(limiter *throttle.Limiter, key string) time.Duration {
+	wait, err := limiter.Fail(key)
+	if err != nil {
+		log.Printf("failed to record login failure: %v", err)
+	}
+	return wait
+}
+
+func writeTooManyAttempts(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", retryAfterSeconds(wait))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many failed login attempts, please try again later",
+		"retry_after": int(math.Ceil(wait.Seconds())),
+	})
+}
+
+func retryAfterSeconds(wait time.Duration) string {
+	return fmt.Sprint(int(math.Ceil(wait.Seconds())))
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Mikael88/go-mygram/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServeMedia menyajikan file dari local storage. URL harus bertanda tangan
+// karena hanya respons API yang sudah memeriksa visibilitas foto yang
+// membagikannya
+func ServeMedia(c *gin.Context) {
+	local, ok := storage.Default.(*storage.LocalStorage)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if !local.Verify(key, c.Query("expires"), c.Query("signature")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired media URL"})
+		return
+	}
+
+	path, err := local.Path(key)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.File(path)
+}
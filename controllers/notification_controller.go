@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/notifications"
+	"github.com/Mikael88/go-mygram/pagination"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// notificationPage adalah urutan yang diterima GetNotifications. Notifikasi
+// gabungan naik ke atas setiap kali ada kejadian baru.
+var notificationPage = pagination.Options[models.Notification]{
+	Sorts: map[string]pagination.Sort[models.Notification]{
+		"updated_at": {Column: "updated_at", Key: func(n models.Notification) any { return n.UpdatedAt }},
+	},
+	DefaultSort: "-updated_at",
+	ID:          func(n models.Notification) uint { return n.ID },
+}
+
+// notificationsResponse adalah satu halaman notifikasi beserta jumlah yang belum dibaca
+type notificationsResponse struct {
+	pagination.Response
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// GetNotifications menampilkan kotak masuk notifikasi user yang sedang login.
+// ?unread=true hanya menampilkan notifikasi yang belum dibaca.
+func GetNotifications(c *gin.Context) {
+	page, err := pagination.Parse(c, notificationPage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId := c.GetUint("userId")
+	query := config.DB.Preload("Actor").Where("user_id = ?", userId)
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var items []models.Notification
+	next, err := page.Find(query, &items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	unread, err := unreadNotificationCount(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	formattedNotifications := make([]gin.H, len(items))
+	for i, notification := range items {
+		formattedNotifications[i] = formatNotification(notification)
+	}
+
+	c.JSON(http.StatusOK, notificationsResponse{Response: page.Response(formattedNotifications, next), UnreadCount: unread})
+}
+
+// GetUnreadNotificationCount menampilkan jumlah notifikasi yang belum dibaca
+func GetUnreadNotificationCount(c *gin.Context) {
+	unread, err := unreadNotificationCount(c.GetUint("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"unread_count": unread}})
+}
+
+// MarkNotificationRead menandai satu notifikasi sudah dibaca
+func MarkNotificationRead(c *gin.Context) {
+	notification := c.MustGet("notification").(*models.Notification)
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		notification.ReadAt = &now
+		if err := notifications.MarkRead(config.DB.Model(notification), now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+			return
+		}
+	}
+
+	if err := config.DB.Preload("Actor").Where("id = ?", notification.ID).First(notification).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load notification"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": formatNotification(*notification)})
+}
+
+// MarkAllNotificationsRead menandai semua notifikasi user sudah dibaca
+func MarkAllNotificationsRead(c *gin.Context) {
+	result := notifications.MarkRead(config.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", c.GetUint("userId")), time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"marked_read": result.RowsAffected, "unread_count": 0}})
+}
+
+// GetNotificationPreferences menampilkan jenis notifikasi yang aktif untuk
+// user yang sedang login
+func GetNotificationPreferences(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": notificationPreferences(user)})
+}
+
+// UpdateNotificationPreferences mengaktifkan atau mematikan jenis notifikasi,
+// misalnya {"comment_created": false}. Jenis yang tidak dikirim tidak berubah.
+func UpdateNotificationPreferences(c *gin.Context) {
+	var input map[string]bool
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for notificationType := range input {
+		if notificationType == models.NotificationSecurity {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Security notifications cannot be disabled"})
+			return
+		}
+		if !configurableNotification(notificationType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown notification type: " + notificationType, "valid_types": models.ConfigurableNotifications})
+			return
+		}
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	preferences := map[string]bool{}
+	for notificationType, enabled := range user.NotificationPreferences {
+		preferences[notificationType] = enabled
+	}
+	for notificationType, enabled := range input {
+		preferences[notificationType] = enabled
+	}
+	user.NotificationPreferences = preferences
+
+	if err := config.DB.Model(&user).Select("notification_preferences").Updates(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": notificationPreferences(user)})
+}
+
+func unreadNotificationCount(userId uint) (int64, error) {
+	var count int64
+	err := config.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userId).Count(&count).Error
+	return count, err
+}
+
+func configurableNotification(notificationType string) bool {
+	for _, t := range models.ConfigurableNotifications {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+func notificationPreferences(user models.User) gin.H {
+	preferences := gin.H{models.NotificationSecurity: true}
+	for _, notificationType := range models.ConfigurableNotifications {
+		preferences[notificationType] = user.WantsNotification(notificationType)
+	}
+	return preferences
+}
+
+// notifyPhotoDeleted memberi tahu user yang pernah berkomentar di foto yang
+// dihapus, lalu menghapus notifikasi lama yang menunjuk ke foto tersebut.
+// Penghapusan oleh moderator dicatat tanpa pelaku.
+func notifyPhotoDeleted(tx *gorm.DB, photo models.Photo, deleterId uint) error {
+	if err := notifications.DeleteTarget(tx, models.NotificationTargetPhoto, photo.ID); err != nil {
+		return err
+	}
+
+	var commenters []uint
+	err := tx.Model(&models.Comment{}).Distinct("user_id").
+		Where("photo_id = ? AND user_id <> ?", photo.ID, deleterId).
+		Pluck("user_id", &commenters).Error
+	if err != nil {
+		return err
+	}
+
+	var actorId uint
+	if deleterId == photo.UserID {
+		actorId = deleterId
+	}
+	for _, commenter := range commenters {
+		err := notifications.Notify(tx, notifications.Event{
+			UserID:     commenter,
+			Type:       models.NotificationPhotoDeleted,
+			ActorID:    actorId,
+			TargetType: models.NotificationTargetPhoto,
+			TargetID:   photo.ID,
+			Data:       map[string]interface{}{"photo_title": photo.Title},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifySecurity mencatat kejadian keamanan akun dari request saat ini
+func notifySecurity(c *gin.Context, tx *gorm.DB, userId uint, event string) error {
+	return notifications.Security(tx, userId, event, c.ClientIP(), c.Request.UserAgent())
+}
+
+// logSecurityEvent mencatat kejadian keamanan di luar transaksi. Kegagalan
+// hanya di-log agar tidak menggagalkan aksi yang sudah berhasil.
+func logSecurityEvent(c *gin.Context, userId uint, event string) {
+	if err := notifySecurity(c, config.DB, userId, event); err != nil {
+		log.Printf("failed to record security notification %s for user %d: %v", event, userId, err)
+	}
+}
+
+func formatNotification(notification models.Notification) gin.H {
+	formatted := gin.H{
+		"id":          notification.ID,
+		"type":        notification.Type,
+		"message":     notifications.Message(notification),
+		"actor_count": notification.ActorCount,
+		"target_type": notification.TargetType,
+		"target_id":   notification.TargetID,
+		"read":        notification.ReadAt != nil,
+		"read_at":     notification.ReadAt,
+		"created_at":  notification.CreatedAt,
+		"updated_at":  notification.UpdatedAt,
+	}
+	if notification.Actor != nil {
+		formatted["actor"] = gin.H{
+			"id":       notification.Actor.ID,
+			"username": notification.Actor.Username,
+		}
+	}
+	return formatted
+}
@@ -1,11 +1,22 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/hashtag"
+	"github.com/Mikael88/go-mygram/feed"
+	"github.com/Mikael88/go-mygram/imaging"
+	"github.com/Mikael88/go-mygram/jobs"
 	"github.com/Mikael88/go-mygram/models"
-	"github.com/jinzhu/gorm"
+	"github.com/Mikael88/go-mygram/pagination"
+	"github.com/Mikael88/go-mygram/visibility"
+	"gorm.io/gorm"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -14,31 +25,27 @@ import (
 var validate *validator.Validate
 
 type CreatePhotoInput struct {
-	Title    string `json:"title" binding:"required"`
-	Caption  string `json:"caption"`
-	PhotoURL string `json:"photo_url" binding:"required"`
+	Title      string `json:"title" binding:"required"`
+	Caption    string `json:"caption"`
+	PhotoURL   string `json:"photo_url" binding:"required"`
+	Visibility string     `json:"visibility"`
+	Status     string     `json:"status"`
+	PublishAt  *time.Time `json:"publish_at"`
 }
 
+var (
+	errInvalidPhotoVisibility = errors.New("visibility must be public, unlisted or only_me")
+	errInvalidPhotoStatus     = errors.New("status must be draft, scheduled or published")
+	errPublishAtRequired      = errors.New("publish_at is required for scheduled photos")
+	errPublishAtInPast        = errors.New("publish_at must be in the future")
+)
+
 func init() {
 	// Buat objek validator
 	validate = validator.New()
 }
-// Create menambahkan foto baru
+// Create menambahkan foto baru, baik dari upload multipart maupun dari photo_url
 func CreatePhoto(c *gin.Context) {
-	var input CreatePhotoInput
-
-	// Bind request body ke struct input
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Validasi input menggunakan objek validator
-	if err := validate.Struct(input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
 	// Dapatkan ID pengguna dari konteks
 	userId, exists := c.Get("userId")
 	if !exists {
@@ -46,59 +53,193 @@ func CreatePhoto(c *gin.Context) {
 		return
 	}
 
-	// Buat objek foto
-	photo := models.Photo{
-		Title:    input.Title,
-		Caption:  input.Caption,
-		PhotoURL: input.PhotoURL,
-		UserID:   userId.(uint), // Konversi userId menjadi uint
+	var photo models.Photo
+	if c.ContentType() == "multipart/form-data" {
+		uploaded, ok := receivePhotoUpload(c, userId.(uint))
+		if !ok {
+			return
+		}
+		photo = uploaded
+	} else {
+		var input CreatePhotoInput
+
+		// Bind request body ke struct input
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Validasi input menggunakan objek validator
+		if err := validate.Struct(input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Buat objek foto
+		photo = models.Photo{
+			Title:    input.Title,
+			Caption:  input.Caption,
+			PhotoURL: input.PhotoURL,
+		}
+		if err := applyInitialStatus(&photo, input.Visibility, input.Status, input.PublishAt); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 	}
+	photo.UserID = userId.(uint) // Konversi userId menjadi uint
 
-	// Simpan foto ke database
-	err := config.DB.Preload("User", func(db *gorm.DB) *gorm.DB {
-		return db.Select("id", "username")
-	}).Create(&photo).Error
+	// Simpan foto ke database dan sebarkan ke feed follower jika langsung terbit,
+	// atau jadwalkan job penerbitan jika foto dijadwalkan
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&photo).Error; err != nil {
+			return err
+		}
+		if err := hashtag.Sync(tx, models.TagTargetPhoto, photo.ID, photo.UserID, photo.Caption); err != nil {
+			return err
+		}
+		switch photo.Status {
+		case models.PhotoPublished:
+			return feed.Default.PhotoPublished(tx, photo)
+		case models.PhotoScheduled:
+			return enqueuePhotoPublish(tx, photo)
+		}
+		return nil
+	})
 	if err != nil {
+		deleteStoredPhoto(photo)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	photoResponse := models.PhotoResponse{
-		ID:        photo.ID,
-		Title:     photo.Title,
-		Caption:   photo.Caption,
-		PhotoURL:  photo.PhotoURL,
-		UserID:    photo.UserID,
-		CreatedAt: photo.CreatedAt,
-	  }
+	// Variant ukuran foto dibuat di background
+	if photo.StorageKey != "" {
+		imaging.Enqueue(photo.ID)
+	}
 
-	c.JSON(http.StatusCreated, gin.H{"data": photoResponse})
+	c.JSON(http.StatusCreated, gin.H{"data": newPhotoResponse(photo, false)})
 }
-// GET semua foto
+// applyInitialStatus mengisi visibility dan status foto baru. Foto baru bisa
+// dibuat sebagai draft, langsung published, atau scheduled jika publishAt diisi.
+func applyInitialStatus(photo *models.Photo, visibility, status string, publishAt *time.Time) error {
+	if visibility == "" {
+		visibility = models.PhotoPublic
+	}
+	if !models.ValidPhotoVisibility(visibility) {
+		return errInvalidPhotoVisibility
+	}
+
+	if publishAt != nil && status == "" {
+		status = models.PhotoScheduled
+	}
+
+	switch status {
+	case models.PhotoScheduled:
+		if publishAt == nil {
+			return errPublishAtRequired
+		}
+		if !publishAt.After(time.Now()) {
+			return errPublishAtInPast
+		}
+		photo.Status = models.PhotoScheduled
+		photo.PublishAt = publishAt
+	case "", models.PhotoPublished:
+		now := time.Now()
+		photo.Status = models.PhotoPublished
+		photo.PublishedAt = &now
+	case models.PhotoDraft:
+		photo.Status = models.PhotoDraft
+	default:
+		return errInvalidPhotoStatus
+	}
+
+	photo.Visibility = visibility
+	return nil
+}
+// photoPage adalah urutan dan filter yang diterima GetPhotos
+var photoPage = pagination.Options[models.Photo]{
+	Sorts: map[string]pagination.Sort[models.Photo]{
+		"created_at": {Column: "created_at", Key: func(p models.Photo) any { return p.CreatedAt }},
+		"updated_at":   {Column: "updated_at", Key: func(p models.Photo) any { return p.UpdatedAt }},
+		"published_at": {Column: "published_at", Key: func(p models.Photo) any { return p.PublishedTime() }},
+		"id":           {Column: "id", Key: func(p models.Photo) any { return p.ID }},
+	},
+	DefaultSort:   "-created_at",
+	ID:            func(p models.Photo) uint { return p.ID },
+	Filters:       map[string]string{"user_id": "user_id"},
+	CreatedColumn: "created_at",
+}
+// GET semua foto. Default hanya foto published; ?status=draft, ?status=scheduled
+// atau ?status=archived menampilkan foto milik user sendiri dengan status tersebut.
 func GetPhotos(c *gin.Context) {
-    var photos []models.Photo
+	page, err := pagination.Parse(c, photoPage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId := c.GetUint("userId")
+	query := config.DB.Preload("User").Preload("Variants")
+	switch status := c.DefaultQuery("status", models.PhotoPublished); status {
+	case models.PhotoPublished:
+		query = query.Scopes(visibility.ListedPhotos(userId), visibility.Unmuted(userId, "photos.user_id"))
+	case models.PhotoDraft, models.PhotoScheduled, models.PhotoArchived:
+		query = query.Where("user_id = ? AND status = ?", userId, status)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be published, draft, scheduled or archived"})
+		return
+	}
+
+	var photos []models.Photo
+
+	// Query database untuk mendapatkan daftar foto beserta detail pengguna
+	next, err := page.Find(query, &photos)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch photos"})
+		return
+	}
 
-    // Query database untuk mendapatkan daftar foto beserta detail pengguna
-    if err := config.DB.Preload("User").Find(&photos).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch photos"})
-        return
-    }
+	formattedPhotos, err := formatPhotos(c, photos)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch photos"})
+		return
+	}
 
-	if len(photos) == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"message": "No photos found"})
-        return
-    }
+	// Return daftar foto dalam format yang sesuai
+	c.JSON(http.StatusOK, page.Response(formattedPhotos, next))
+}
+// formatPhotos menyusun daftar foto beserta pemilik dan status like user yang sedang login
+func formatPhotos(c *gin.Context, photos []models.Photo) ([]gin.H, error) {
+	photoIds := make([]uint, len(photos))
+	for i, photo := range photos {
+		photoIds[i] = photo.ID
+	}
+	liked, err := likedTargets(c.GetUint("userId"), models.LikeTargetPhoto, photoIds)
+	if err != nil {
+		return nil, err
+	}
 
-	var formattedPhotos []gin.H
+	formattedPhotos := make([]gin.H, 0, len(photos))
 	for _, photo := range photos {
 		formattedPhoto := gin.H{
-			"id":         photo.ID,
-			"title":      photo.Title,
-			"caption":    photo.Caption,
-			"photo_url":  photo.PhotoURL,
-			"user_id":    photo.UserID,
-			"created_at": photo.CreatedAt,
-			"updated_at": photo.UpdatedAt,
+			"id":           photo.ID,
+			"title":        photo.Title,
+			"caption":      photo.Caption,
+			"tags":         hashtag.Parse(photo.Caption),
+			"photo_url":    photoURL(photo),
+			"variants":     photoVariantURLs(photo),
+			"camera_make":  photo.CameraMake,
+			"camera_model": photo.CameraModel,
+			"taken_at":     photo.TakenAt,
+			"location":     photo.PublicLocation(),
+			"like_count":   photo.LikeCount,
+			"liked_by_me":  liked[photo.ID],
+			"visibility":   photo.Visibility,
+			"status":       photo.Status,
+			"published_at": photo.PublishedAt,
+			"publish_at":   photo.PublishAt,
+			"user_id":      photo.UserID,
+			"created_at":   photo.CreatedAt,
+			"updated_at":   photo.UpdatedAt,
 			"user": gin.H{
 				"email":    photo.User.Email,
 				"username": photo.User.Username,
@@ -106,32 +247,36 @@ func GetPhotos(c *gin.Context) {
 		}
 		formattedPhotos = append(formattedPhotos, formattedPhoto)
 	}
-
-    // Return daftar foto dalam format yang sesuai
-    c.JSON(http.StatusOK, formattedPhotos)
+	return formattedPhotos, nil
 }
-// UpdatePhoto mengelola proses pembaruan informasi foto.
-func UpdatePhoto(c *gin.Context) {
-	photoID := c.Param("photoId")
-
+// GetPhoto menampilkan satu foto. Foto unlisted bisa dibuka di sini walaupun
+// tidak muncul di daftar, sedangkan draft, arsip, dan only_me hanya untuk pemiliknya.
+func GetPhoto(c *gin.Context) {
 	var photo models.Photo
-	if err := config.DB.Where("id = ?", photoID).First(&photo).Error; err != nil {
+	err := config.DB.Scopes(visibility.Photos(c.GetUint("userId"))).Preload("User").Preload("Variants").
+		Where("id = ?", c.Param("photoId")).First(&photo).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Photo not found"})
 		return
 	}
-
-	// Periksa apakah pengguna yang meminta pembaruan adalah pemilik foto
-	userId, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch photo"})
 		return
 	}
 
-	if photo.UserID != userId.(uint) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	formattedPhotos, err := formatPhotos(c, []models.Photo{photo})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch photo"})
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{"data": formattedPhotos[0]})
+}
+// UpdatePhoto mengelola proses pembaruan informasi foto.
+func UpdatePhoto(c *gin.Context) {
+	// Foto sudah dimuat dan dicek kepemilikannya oleh AuthorizeOwner
+	photo := c.MustGet("photo").(*models.Photo)
+
 	var updatePhoto models.Photo
 	if err := c.ShouldBindJSON(&updatePhoto); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -140,50 +285,168 @@ func UpdatePhoto(c *gin.Context) {
 
 	photo.Title = updatePhoto.Title
 	photo.Caption = updatePhoto.Caption
-	photo.PhotoURL = updatePhoto.PhotoURL
+	if updatePhoto.Visibility != "" {
+		if !models.ValidPhotoVisibility(updatePhoto.Visibility) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidPhotoVisibility.Error()})
+			return
+		}
+		photo.Visibility = updatePhoto.Visibility
+	}
+	// Foto hasil upload tetap memakai file yang tersimpan
+	if photo.StorageKey == "" {
+		photo.PhotoURL = updatePhoto.PhotoURL
+	}
 
-	if err := config.DB.Save(&photo).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(photo).Error; err != nil {
+			return err
+		}
+		return hashtag.Sync(tx, models.TagTargetPhoto, photo.ID, photo.UserID, photo.Caption)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update photo"})
 		return
 	}
 
-	photoResponse := models.PhotoResponse{
-		ID:        photo.ID,
-		Title:     photo.Title,
-		Caption:   photo.Caption,
-		PhotoURL:  photo.PhotoURL,
-		UserID:    photo.UserID,
-		CreatedAt: photo.CreatedAt,
-	  }
-
-	c.JSON(http.StatusOK, gin.H{"data": photoResponse})
+	c.JSON(http.StatusOK, gin.H{"data": newPhotoResponse(*photo, isLikedBy(c.GetUint("userId"), models.LikeTargetPhoto, photo.ID))})
 }
 // DeletePhoto mengelola proses penghapusan foto.
 func DeletePhoto(c *gin.Context) {
-	photoID := c.Param("photoId")
+	photo := c.MustGet("photo").(*models.Photo)
 
-	var photo models.Photo
-	if err := config.DB.Where("id = ?", photoID).First(&photo).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Photo not found"})
+	if err := config.DB.Where("photo_id = ?", photo.ID).Find(&photo.Variants).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete photo"})
 		return
 	}
 
-	// Periksa apakah pengguna yang meminta penghapusan adalah pemilik foto
-	userId, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	// Komentar, like, dan variant foto ikut dihapus agar tidak tertinggal tanpa foto
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var commentIds []uint
+		if err := tx.Model(&models.Comment{}).Where("photo_id = ?", photo.ID).Pluck("id", &commentIds).Error; err != nil {
+			return err
+		}
+		if err := deleteTargetLikes(tx, models.LikeTargetComment, commentIds); err != nil {
+			return err
+		}
+		if err := deleteTargetLikes(tx, models.LikeTargetPhoto, []uint{photo.ID}); err != nil {
+			return err
+		}
+		if err := hashtag.Unlink(tx, models.TagTargetComment, commentIds); err != nil {
+			return err
+		}
+		if err := hashtag.Unlink(tx, models.TagTargetPhoto, []uint{photo.ID}); err != nil {
+			return err
+		}
+		if err := notifyPhotoDeleted(tx, *photo, c.GetUint("userId")); err != nil {
+			return err
+		}
+		if err := models.RemoveSearchDocuments(tx, models.SearchKindComment, commentIds); err != nil {
+			return err
+		}
+		if err := tx.Where("photo_id = ?", photo.ID).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("photo_id = ?", photo.ID).Delete(&models.PhotoVariant{}).Error; err != nil {
+			return err
+		}
+		if err := feed.Default.PhotoDeleted(tx, photo.ID); err != nil {
+			return err
+		}
+		if err := jobs.Cancel(tx, publishPhotoJobKey(photo.ID)); err != nil {
+			return err
+		}
+		return tx.Delete(photo).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete photo"})
 		return
 	}
 
-	if photo.UserID != userId.(uint) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	deleteStoredPhoto(*photo)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Photo deleted successfully"})
+}
+// PhotoLocationInput adalah struktur input untuk mengatur publikasi lokasi foto
+type PhotoLocationInput struct {
+	Publish *bool `json:"publish" binding:"required"`
+}
+// UpdatePhotoLocation mengatur apakah lokasi kasar foto ditampilkan ke publik
+func UpdatePhotoLocation(c *gin.Context) {
+	photo := c.MustGet("photo").(*models.Photo)
+
+	var input PhotoLocationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := config.DB.Delete(&photo).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete photo"})
+	if *input.Publish && (photo.Latitude == nil || photo.Longitude == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This photo has no stored location"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Photo deleted successfully"})
+	if err := config.DB.Model(photo).Update("location_public", *input.Publish).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update photo location"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": newPhotoResponse(*photo, isLikedBy(c.GetUint("userId"), models.LikeTargetPhoto, photo.ID))})
+}
+// PublishPhoto menerbitkan foto draft, atau foto scheduled sebelum jadwalnya
+func PublishPhoto(c *gin.Context) {
+	changePhotoStatus(c, models.PhotoPublished, models.PhotoDraft, models.PhotoScheduled)
+}
+// ArchivePhoto menyembunyikan foto published dari user lain tanpa menghapusnya
+func ArchivePhoto(c *gin.Context) {
+	changePhotoStatus(c, models.PhotoArchived, models.PhotoPublished)
+}
+// UnarchivePhoto menerbitkan kembali foto yang diarsipkan
+func UnarchivePhoto(c *gin.Context) {
+	changePhotoStatus(c, models.PhotoPublished, models.PhotoArchived)
+}
+// changePhotoStatus memindahkan foto ke status to jika statusnya saat ini salah
+// satu dari from. Foto yang sudah berstatus to dikembalikan apa adanya.
+func changePhotoStatus(c *gin.Context, to string, from ...string) {
+	photo := c.MustGet("photo").(*models.Photo)
+
+	if photo.Status != to {
+		if !slices.Contains(from, photo.Status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Only %s photos can be changed to %s", strings.Join(from, " or "), to)})
+			return
+		}
+
+		scheduled := photo.Status == models.PhotoScheduled
+		updates := map[string]interface{}{"status": to}
+		if scheduled {
+			photo.PublishAt = nil
+			updates["publish_at"] = nil
+		}
+		if to == models.PhotoPublished && photo.PublishedAt == nil {
+			now := time.Now()
+			photo.PublishedAt = &now
+			updates["published_at"] = now
+		}
+		photo.Status = to
+
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(photo).Updates(updates).Error; err != nil {
+				return err
+			}
+			if scheduled {
+				if err := jobs.Cancel(tx, publishPhotoJobKey(photo.ID)); err != nil {
+					return err
+				}
+			}
+			if to != models.PhotoPublished {
+				return nil
+			}
+			return feed.Default.PhotoPublished(tx, *photo)
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update photo status"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": newPhotoResponse(*photo, isLikedBy(c.GetUint("userId"), models.LikeTargetPhoto, photo.ID))})
 }
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/feed"
+	"github.com/Mikael88/go-mygram/jobs"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/pagination"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// publishPhotoJob adalah tipe job yang menerbitkan foto scheduled
+const publishPhotoJob = "publish_photo"
+
+// SchedulePhotoInput adalah struktur input untuk menjadwalkan ulang foto
+type SchedulePhotoInput struct {
+	PublishAt *time.Time `json:"publish_at" binding:"required"`
+}
+
+type publishPhotoPayload struct {
+	PhotoID uint `json:"photo_id"`
+}
+
+// scheduledPhotoPage adalah urutan yang diterima GetScheduledPhotos
+var scheduledPhotoPage = pagination.Options[models.Photo]{
+	Sorts: map[string]pagination.Sort[models.Photo]{
+		"publish_at": {Column: "publish_at", Key: func(p models.Photo) any {
+			if p.PublishAt == nil {
+				return time.Time{}
+			}
+			return *p.PublishAt
+		}},
+		"created_at": {Column: "created_at", Key: func(p models.Photo) any { return p.CreatedAt }},
+	},
+	DefaultSort: "publish_at",
+	ID:          func(p models.Photo) uint { return p.ID },
+}
+
+// RegisterJobs mendaftarkan handler job background milik controller.
+// Dipanggil sebelum jobs.Start.
+func RegisterJobs() {
+	jobs.Register(publishPhotoJob, publishScheduledPhoto)
+}
+
+// GetScheduledPhotos menampilkan foto scheduled milik user yang sedang login,
+// yang paling cepat terbit lebih dulu
+func GetScheduledPhotos(c *gin.Context) {
+	page, err := pagination.Parse(c, scheduledPhotoPage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var photos []models.Photo
+	query := config.DB.Preload("User").Preload("Variants").
+		Where("user_id = ? AND status = ?", c.GetUint("userId"), models.PhotoScheduled)
+	next, err := page.Find(query, &photos)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled photos"})
+		return
+	}
+
+	formattedPhotos, err := formatPhotos(c, photos)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled photos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page.Response(formattedPhotos, next))
+}
+
+// SchedulePhoto menjadwalkan foto draft atau mengubah jadwal foto scheduled
+func SchedulePhoto(c *gin.Context) {
+	photo := c.MustGet("photo").(*models.Photo)
+
+	var input SchedulePhotoInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if photo.Status != models.PhotoDraft && photo.Status != models.PhotoScheduled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only draft or scheduled photos can be scheduled"})
+		return
+	}
+	if !input.PublishAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errPublishAtInPast.Error()})
+		return
+	}
+
+	photo.Status = models.PhotoScheduled
+	photo.PublishAt = input.PublishAt
+
+	// Job lama diganti agar foto tidak terbit pada jadwal sebelumnya
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(photo).Updates(map[string]interface{}{"status": photo.Status, "publish_at": photo.PublishAt}).Error
+		if err != nil {
+			return err
+		}
+		if err := jobs.Cancel(tx, publishPhotoJobKey(photo.ID)); err != nil {
+			return err
+		}
+		return enqueuePhotoPublish(tx, *photo)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule photo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": newPhotoResponse(*photo, isLikedBy(c.GetUint("userId"), models.LikeTargetPhoto, photo.ID))})
+}
+
+// UnschedulePhoto membatalkan jadwal terbit dan mengembalikan foto menjadi draft
+func UnschedulePhoto(c *gin.Context) {
+	photo := c.MustGet("photo").(*models.Photo)
+
+	if photo.Status != models.PhotoScheduled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Photo is not scheduled"})
+		return
+	}
+
+	photo.Status = models.PhotoDraft
+	photo.PublishAt = nil
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(photo).Updates(map[string]interface{}{"status": photo.Status, "publish_at": nil}).Error
+		if err != nil {
+			return err
+		}
+		return jobs.Cancel(tx, publishPhotoJobKey(photo.ID))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel scheduled photo"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": newPhotoResponse(*photo, isLikedBy(c.GetUint("userId"), models.LikeTargetPhoto, photo.ID))})
+}
+
+// enqueuePhotoPublish menyimpan job penerbitan foto pada PublishAt
+func enqueuePhotoPublish(tx *gorm.DB, photo models.Photo) error {
+	return jobs.Enqueue(tx, publishPhotoJob, publishPhotoJobKey(photo.ID), publishPhotoPayload{PhotoID: photo.ID}, *photo.PublishAt)
+}
+
+func publishPhotoJobKey(photoID uint) string {
+	return fmt.Sprintf("%s:%d", publishPhotoJob, photoID)
+}
+
+// publishScheduledPhoto menerbitkan foto scheduled. Job bisa berjalan lebih
+// dari sekali, jadi foto yang sudah terbit, dihapus, atau dijadwalkan ulang
+// ke waktu lain dilewati.
+func publishScheduledPhoto(ctx context.Context, job models.Job) error {
+	var payload publishPhotoPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	return config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var photo models.Photo
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", payload.PhotoID).Take(&photo).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if photo.Status != models.PhotoScheduled || photo.PublishAt == nil || photo.PublishAt.After(now) {
+			return nil
+		}
+
+		photo.Status = models.PhotoPublished
+		photo.PublishedAt = &now
+		photo.PublishAt = nil
+		err = tx.Model(&photo).Updates(map[string]interface{}{
+			"status":       photo.Status,
+			"published_at": now,
+			"publish_at":   nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return feed.Default.PhotoPublished(tx, photo)
+	})
+}
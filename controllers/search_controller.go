@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Mikael88/go-mygram/auth"
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/middlewares"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/search"
+	"github.com/Mikael88/go-mygram/visibility"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// searchScopes adalah scope token yang dibutuhkan setiap jenis hasil pencarian
+var searchScopes = map[string]string{
+	"users":    auth.ScopeUsersRead,
+	"photos":   auth.ScopePhotosRead,
+	"comments": auth.ScopeCommentsRead,
+}
+
+// GetSearch mencari user, foto, dan komentar dengan kata kunci ?q=. Hasil
+// dikelompokkan per jenis dan hanya berisi konten yang boleh dilihat user.
+// ?type= membatasi jenis hasil, misalnya type=users,photos, dan ?limit=
+// mengatur jumlah hasil per jenis. Personal access token hanya mendapat jenis
+// hasil yang sesuai dengan scope-nya.
+func GetSearch(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if len(search.Terms(q)) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q must contain at least one word"})
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 50"})
+			return
+		}
+		limit = parsed
+	}
+
+	types := map[string]bool{}
+	if value := c.Query("type"); value != "" {
+		for _, t := range strings.Split(value, ",") {
+			scope, ok := searchScopes[t]
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "type must be a comma separated list of users, photos and comments"})
+				return
+			}
+			if !middlewares.HasScope(c, scope) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Token is missing the required scope: " + scope})
+				return
+			}
+			types[t] = true
+		}
+	} else {
+		for t, scope := range searchScopes {
+			if middlewares.HasScope(c, scope) {
+				types[t] = true
+			}
+		}
+		if len(types) == 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Token has no scope that allows searching"})
+			return
+		}
+	}
+
+	userId := c.GetUint("userId")
+	result := gin.H{}
+
+	if types["users"] {
+		users, err := searchUsers(userId, q, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search users"})
+			return
+		}
+		result["users"] = users
+	}
+
+	if types["photos"] {
+		photos, err := searchPhotos(c, userId, q, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search photos"})
+			return
+		}
+		result["photos"] = photos
+	}
+
+	if types["comments"] {
+		comments, err := searchComments(c, userId, q, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search comments"})
+			return
+		}
+		result["comments"] = comments
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+func searchUsers(userId uint, q string, limit int) ([]gin.H, error) {
+	scoped := config.DB.Model(&models.User{}).Scopes(visibility.Users(userId, "users.id"))
+	ids, err := search.Default.Search(scoped, models.SearchKindUser, q, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []models.User
+	if err := loadRanked(config.DB, ids, &users); err != nil {
+		return nil, err
+	}
+
+	formattedUsers := make([]gin.H, 0, len(ids))
+	for _, user := range orderByIds(ids, users, func(u models.User) uint { return u.ID }) {
+		formattedUsers = append(formattedUsers, gin.H{
+			"id":         user.ID,
+			"username":   user.Username,
+			"is_private": user.IsPrivate,
+		})
+	}
+	return formattedUsers, nil
+}
+
+func searchPhotos(c *gin.Context, userId uint, q string, limit int) ([]gin.H, error) {
+	scoped := config.DB.Model(&models.Photo{}).
+		Scopes(visibility.ListedPhotos(userId), visibility.Unmuted(userId, "photos.user_id"))
+	ids, err := search.Default.Search(scoped, models.SearchKindPhoto, q, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var photos []models.Photo
+	if err := loadRanked(config.DB.Preload("User").Preload("Variants"), ids, &photos); err != nil {
+		return nil, err
+	}
+	return formatPhotos(c, orderByIds(ids, photos, func(p models.Photo) uint { return p.ID }))
+}
+
+func searchComments(c *gin.Context, userId uint, q string, limit int) ([]map[string]interface{}, error) {
+	listedPhotos := config.DB.Model(&models.Photo{}).Select("id").Scopes(visibility.ListedPhotos(userId))
+	scoped := config.DB.Model(&models.Comment{}).
+		Scopes(visibility.Comments(userId), visibility.Unmuted(userId, "comments.user_id")).
+		Where("comments.photo_id IN (?)", listedPhotos)
+	ids, err := search.Default.Search(scoped, models.SearchKindComment, q, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []models.Comment
+	if err := loadRanked(config.DB.Preload("User").Preload("Photo.User"), ids, &comments); err != nil {
+		return nil, err
+	}
+	comments = orderByIds(ids, comments, func(comment models.Comment) uint { return comment.ID })
+
+	liked, err := likedCommentIds(c, comments)
+	if err != nil {
+		return nil, err
+	}
+
+	formattedComments := make([]map[string]interface{}, len(comments))
+	for i, comment := range comments {
+		formattedComments[i] = formatComment(comment, liked[comment.ID])
+	}
+	return formattedComments, nil
+}
+
+// loadRanked memuat baris dengan ID hasil pencarian
+func loadRanked(query *gorm.DB, ids []uint, dest interface{}) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return query.Where("id IN ?", ids).Find(dest).Error
+}
+
+// orderByIds mengurutkan rows sesuai urutan relevansi ids
+func orderByIds[T any](ids []uint, rows []T, id func(T) uint) []T {
+	byId := make(map[uint]T, len(rows))
+	for _, row := range rows {
+		byId[id(row)] = row
+	}
+
+	ordered := make([]T, 0, len(rows))
+	for _, rowId := range ids {
+		if row, ok := byId[rowId]; ok {
+			ordered = append(ordered, row)
+		}
+	}
+	return ordered
+}
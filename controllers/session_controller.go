@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Mikael88/go-mygram/auth"
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/notifications"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// RefreshTokenInput adalah struktur input untuk refresh dan logout
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// issueTokens membuat sesi baru untuk user dan mengembalikan access token serta refresh token
+func issueTokens(c *gin.Context, user models.User) (gin.H, error) {
+	refreshToken, refreshHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := models.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: refreshHash,
+		UserAgent:        c.Request.UserAgent(),
+		IP:               c.ClientIP(),
+		ExpiresAt:        time.Now().Add(refreshTokenTTL),
+	}
+	if err := config.DB.Create(&session).Error; err != nil {
+		return nil, err
+	}
+	logSecurityEvent(c, user.ID, notifications.SecurityNewLogin)
+
+	token, err := generateJWTToken(user.ID, session.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int(accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// RefreshToken menukar refresh token yang valid dengan pasangan token baru (rotasi)
+func RefreshToken(c *gin.Context) {
+	var input RefreshTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	oldHash := auth.HashToken(input.RefreshToken)
+
+	var session models.Session
+	if err := config.DB.Where("refresh_token_hash = ?", oldHash).First(&session).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	if !session.Active() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has expired or been revoked"})
+		return
+	}
+
+	// Role dibaca ulang agar perubahan role berlaku saat token diperbarui
+	var user models.User
+	if err := config.DB.Select("id", "role").Where("id = ?", session.UserID).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	newToken, newHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	// Update bersyarat agar refresh token lama hanya bisa dipakai sekali
+	result := config.DB.Model(&models.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, oldHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": newHash,
+			"expires_at":         time.Now().Add(refreshTokenTTL),
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	token, err := generateJWTToken(session.UserID, session.ID, user.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         token,
+		"refresh_token": newToken,
+		"expires_in":    int(accessTokenTTL.Seconds()),
+	})
+}
+
+// LogoutUser mencabut sesi milik access token yang sedang dipakai
+func LogoutUser(c *gin.Context) {
+	sessionId, exists := c.Get("sessionId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := revokeSessions("id = ?", sessionId); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// revokeSessions menandai sesi yang cocok dengan kondisi sebagai dicabut
+func revokeSessions(query string, args ...interface{}) error {
+	return config.DB.Model(&models.Session{}).
+		Where(query, args...).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", time.Now()).Error
+}
@@ -2,10 +2,10 @@ package controllers
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/Mikael88/go-mygram/config"
 	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/pagination"
 
 	"github.com/gin-gonic/gin"
 )
@@ -53,6 +53,17 @@ func CreateSocialMedia(c *gin.Context) {
         "created_at":      socialMedia.CreatedAt,
     })
 }
+// socialMediaPage adalah urutan dan filter yang diterima GetSocialMedias
+var socialMediaPage = pagination.Options[models.SocialMedia]{
+    Sorts: map[string]pagination.Sort[models.SocialMedia]{
+        "created_at": {Column: "created_at", Key: func(s models.SocialMedia) any { return s.CreatedAt }},
+        "name":       {Column: "name", Key: func(s models.SocialMedia) any { return s.Name }},
+        "id":         {Column: "id", Key: func(s models.SocialMedia) any { return s.ID }},
+    },
+    DefaultSort:   "-created_at",
+    ID:            func(s models.SocialMedia) uint { return s.ID },
+    CreatedColumn: "created_at",
+}
 // GetSocialMedias mengambil daftar media sosial
 func GetSocialMedias(c *gin.Context) {
     // Dapatkan ID pengguna dari konteks
@@ -62,16 +73,18 @@ func GetSocialMedias(c *gin.Context) {
         return
     }
 
-    var socialMedias []models.SocialMedia
-
-    // Ambil semua media sosial milik pengguna yang diautentikasi dari database
-    if err := config.DB.Where("user_id = ?", userId.(uint)).Preload("User").Find(&socialMedias).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    page, err := pagination.Parse(c, socialMediaPage)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
-    if len(socialMedias) == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"message": "No social media data found"})
+    var socialMedias []models.SocialMedia
+
+    // Ambil media sosial milik pengguna yang diautentikasi dari database
+    next, err := page.Find(config.DB.Where("user_id = ?", userId.(uint)).Preload("User"), &socialMedias)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
 
@@ -94,7 +107,7 @@ func GetSocialMedias(c *gin.Context) {
     }
 
     // Kembalikan daftar media sosial dalam format yang diinginkan
-    c.JSON(http.StatusOK, gin.H{"social_medias": formattedSocialMedias})
+    c.JSON(http.StatusOK, page.Response(formattedSocialMedias, next))
 }
 // UpdateSocialMediaInput adalah struktur untuk validasi input saat memperbarui data sosial media
 type UpdateSocialMediaInput struct {
@@ -102,24 +115,8 @@ type UpdateSocialMediaInput struct {
     SocialMediaURL string `json:"social_media_url" binding:"required"`
 }
 func UpdateSocialMedia(c *gin.Context) {
-    socialMediaID, err := strconv.Atoi(c.Param("socialMediaId"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid social media ID"})
-        return
-    }
-
-    // Get authenticated user ID
-    userId, exists := c.Get("userId")
-    if !exists {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-        return
-    }
-
-    var socialMedia models.SocialMedia
-    if err := config.DB.Where("id = ? AND user_id = ?", socialMediaID, userId.(uint)).First(&socialMedia).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Social media not found or unauthorized"})
-        return
-    }
+    // Data media sosial sudah dimuat dan dicek kepemilikannya oleh AuthorizeOwner
+    socialMedia := c.MustGet("socialMedia").(*models.SocialMedia)
 
     var updateInput UpdateSocialMediaInput
     if err := c.ShouldBindJSON(&updateInput); err != nil {
@@ -130,7 +127,7 @@ func UpdateSocialMedia(c *gin.Context) {
     socialMedia.Name = updateInput.Name
     socialMedia.SocialMediaURL = updateInput.SocialMediaURL
 
-    if err := config.DB.Save(&socialMedia).Error; err != nil {
+    if err := config.DB.Save(socialMedia).Error; err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update social media"})
         return
     }
@@ -145,27 +142,9 @@ func UpdateSocialMedia(c *gin.Context) {
 }
 // DeleteSocialMedia mengelola proses penghapusan data sosial media
 func DeleteSocialMedia(c *gin.Context) {
-	socialMediaID := c.Param("socialMediaId")
-
-	var socialMedia models.SocialMedia
-	if err := config.DB.Where("id = ?", socialMediaID).First(&socialMedia).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Social media not found"})
-		return
-	}
-
-	// Periksa apakah pengguna yang meminta penghapusan adalah pemilik data sosial media
-	userId, exists := c.Get("userId")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-
-	if socialMedia.UserID != userId.(uint) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
+	socialMedia := c.MustGet("socialMedia").(*models.SocialMedia)
 
-	if err := config.DB.Delete(&socialMedia).Error; err != nil {
+	if err := config.DB.Delete(socialMedia).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete social media"})
 		return
 	}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/hashtag"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/pagination"
+	"github.com/Mikael88/go-mygram/trending"
+	"github.com/Mikael88/go-mygram/visibility"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trendingPage adalah urutan yang diterima GetTrendingTags
+var trendingPage = pagination.Options[models.TrendingTag]{
+	Sorts: map[string]pagination.Sort[models.TrendingTag]{
+		"score": {Column: "score", Key: func(t models.TrendingTag) any { return t.Score }},
+	},
+	DefaultSort: "-score",
+	ID:          func(t models.TrendingTag) uint { return t.ID },
+}
+
+// GetTrendingTags menampilkan hashtag yang sedang ramai dari hasil perhitungan
+// terakhir. ?window= memilih 1h, 24h, atau 7d dan ?min_users= mengatur jumlah
+// minimal user berbeda yang memakai tag.
+func GetTrendingTags(c *gin.Context) {
+	page, err := pagination.Parse(c, trendingPage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	window := c.DefaultQuery("window", trending.DefaultWindow)
+	if _, ok := trending.Windows[window]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window must be 1h, 24h or 7d"})
+		return
+	}
+
+	minUsers := trending.MinUsers()
+	if value := c.Query("min_users"); value != "" {
+		if minUsers, err = strconv.Atoi(value); err != nil || minUsers < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "min_users must be a positive integer"})
+			return
+		}
+	}
+
+	var tags []models.TrendingTag
+	query := config.DB.Preload("Tag").Where("time_window = ? AND distinct_users >= ?", window, minUsers)
+	next, err := page.Find(query, &tags)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trending tags"})
+		return
+	}
+
+	formattedTags := make([]gin.H, len(tags))
+	for i, tag := range tags {
+		formattedTags[i] = gin.H{
+			"tag":            tag.Tag.Name,
+			"score":          tag.Score,
+			"uses":           tag.Uses,
+			"distinct_users": tag.DistinctUsers,
+			"window":         tag.Window,
+			"computed_at":    tag.ComputedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, page.Response(formattedTags, next))
+}
+
+// GetTagPhotos menampilkan foto yang caption-nya memuat hashtag pada path.
+// Tag dicocokkan dalam bentuk ternormalisasi, jadi /tags/Café dan /tags/café sama.
+func GetTagPhotos(c *gin.Context) {
+	page, err := pagination.Parse(c, photoPage)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := hashtag.Normalize(c.Param("tag"))
+	if !hashtag.Valid(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hashtag"})
+		return
+	}
+
+	userId := c.GetUint("userId")
+	taggedPhotos := config.DB.Model(&models.TagLink{}).Select("tag_links.target_id").
+		Joins("JOIN tags ON tags.id = tag_links.tag_id").
+		Where("tags.name = ? AND tag_links.target_type = ?", name, models.TagTargetPhoto)
+	query := config.DB.Scopes(visibility.ListedPhotos(userId), visibility.Unmuted(userId, "photos.user_id")).
+		Preload("User").Preload("Variants").
+		Where("photos.id IN (?)", taggedPhotos)
+
+	var photos []models.Photo
+	next, err := page.Find(query, &photos)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch photos"})
+		return
+	}
+
+	formattedPhotos, err := formatPhotos(c, photos)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch photos"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page.Response(formattedPhotos, next))
+}
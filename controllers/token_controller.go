@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Mikael88/go-mygram/auth"
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/notifications"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const maxTokenLifetimeDays = 365
+
+// CreateTokenInput adalah struktur input untuk membuat personal access token
+type CreateTokenInput struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"min=0"`
+}
+
+// CreatePersonalAccessToken membuat token API baru. Token mentah hanya ditampilkan sekali.
+func CreatePersonalAccessToken(c *gin.Context) {
+	var input CreateTokenInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	for _, scope := range input.Scopes {
+		if !auth.ValidScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope: " + scope, "valid_scopes": auth.Scopes})
+			return
+		}
+	}
+
+	if input.ExpiresInDays == 0 {
+		input.ExpiresInDays = 30
+	}
+	if input.ExpiresInDays > maxTokenLifetimeDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tokens can be valid for at most 365 days"})
+		return
+	}
+	expiresAt := time.Now().AddDate(0, 0, input.ExpiresInDays)
+
+	secret, _, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	token := auth.PersonalAccessTokenPrefix + secret
+
+	pat := models.PersonalAccessToken{
+		UserID:    userId.(uint),
+		Name:      input.Name,
+		TokenHash: auth.HashToken(token),
+		Hint:      token[len(token)-4:],
+		Scopes:    strings.Join(input.Scopes, " "),
+		ExpiresAt: &expiresAt,
+	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&pat).Error; err != nil {
+			return err
+		}
+		return notifySecurity(c, tx, pat.UserID, notifications.SecurityTokenCreated)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":  formatPersonalAccessToken(pat),
+		"token": token,
+	})
+}
+
+// GetPersonalAccessTokens menampilkan token milik user yang belum dicabut
+func GetPersonalAccessTokens(c *gin.Context) {
+	userId, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var tokens []models.PersonalAccessToken
+	if err := config.DB.Where("user_id = ? AND revoked_at IS NULL", userId).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tokens"})
+		return
+	}
+
+	formattedTokens := make([]gin.H, len(tokens))
+	for i, token := range tokens {
+		formattedTokens[i] = formatPersonalAccessToken(token)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": formattedTokens})
+}
+
+// RevokePersonalAccessToken mencabut token milik user
+func RevokePersonalAccessToken(c *gin.Context) {
+	userId, exists := c.Get("userId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result := config.DB.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", c.Param("tokenId"), userId).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully"})
+}
+
+func formatPersonalAccessToken(token models.PersonalAccessToken) gin.H {
+	return gin.H{
+		"id":           token.ID,
+		"name":         token.Name,
+		"hint":         token.Hint,
+		"scopes":       token.ScopeList(),
+		"expires_at":   token.ExpiresAt,
+		"last_used_at": token.LastUsedAt,
+		"created_at":   token.CreatedAt,
+	}
+}
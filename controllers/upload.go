@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Mikael88/go-mygram/hashtag"
+	"github.com/Mikael88/go-mygram/imaging"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultMaxUploadBytes = 10 << 20
+
+// allowedImageTypes memetakan content type hasil sniffing ke ekstensi file
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// UploadPhotoInput adalah field form yang dikirim bersama file foto
+type UploadPhotoInput struct {
+	Title   string `form:"title" binding:"required"`
+	Caption string `form:"caption"`
+	// KeepMetadata adalah daftar field EXIF yang disimpan, dipisahkan koma:
+	// camera, taken_at, location. Default diambil dari PHOTO_KEEP_METADATA.
+	KeepMetadata    *string    `form:"keep_metadata"`
+	PublishLocation bool       `form:"publish_location"`
+	Visibility      string     `form:"visibility"`
+	Status          string     `form:"status"`
+	PublishAt       *time.Time `form:"publish_at"`
+}
+
+// receivePhotoUpload membaca upload multipart, memvalidasi jenis dan ukuran
+// file berdasarkan isinya, lalu menyimpannya ke storage
+func receivePhotoUpload(c *gin.Context, userId uint) (models.Photo, bool) {
+	maxBytes := maxUploadBytes()
+
+	// Sisakan ruang untuk field form lain di luar file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+1<<20)
+
+	var input UploadPhotoInput
+	if err := c.ShouldBind(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return models.Photo{}, false
+	}
+
+	var photo models.Photo
+	if err := applyInitialStatus(&photo, input.Visibility, input.Status, input.PublishAt); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return models.Photo{}, false
+	}
+
+	fileHeader, err := c.FormFile("photo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Photo file is required"})
+		return models.Photo{}, false
+	}
+	if fileHeader.Size > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Photo must not be larger than %d bytes", maxBytes)})
+		return models.Photo{}, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read photo file"})
+		return models.Photo{}, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read photo file"})
+		return models.Photo{}, false
+	}
+	if int64(len(data)) > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Photo must not be larger than %d bytes", maxBytes)})
+		return models.Photo{}, false
+	}
+
+	// Content type ditentukan dari isi file, bukan dari header yang dikirim klien
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedImageTypes[contentType]
+	if !ok {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Only JPEG, PNG, GIF and WebP images are allowed"})
+		return models.Photo{}, false
+	}
+
+	// Metadata EXIF/XMP/GPS selalu dihapus dari file yang disimpan
+	data, meta, err := imaging.StripMetadata(data, contentType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Photo file is not a valid image"})
+		return models.Photo{}, false
+	}
+
+	key, err := storage.NewKey(fmt.Sprintf("photos/%d", userId), ext)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store photo"})
+		return models.Photo{}, false
+	}
+
+	if err := storage.Default.Put(c.Request.Context(), key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		log.Printf("failed to store photo %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store photo"})
+		return models.Photo{}, false
+	}
+
+	photo.Title = input.Title
+	photo.Caption = input.Caption
+	photo.StorageKey = key
+	photo.ContentType = contentType
+
+	keep := os.Getenv("PHOTO_KEEP_METADATA")
+	if input.KeepMetadata != nil {
+		keep = *input.KeepMetadata
+	}
+	keepPhotoMetadata(&photo, meta, keep, input.PublishLocation)
+
+	return photo, true
+}
+
+// keepPhotoMetadata menyalin field EXIF yang dipilih pemilik ke kolom foto.
+// Lokasi hanya disimpan dalam bentuk kasar.
+func keepPhotoMetadata(photo *models.Photo, meta imaging.Metadata, keep string, publishLocation bool) {
+	fields := map[string]bool{}
+	for _, field := range strings.Split(keep, ",") {
+		fields[strings.TrimSpace(field)] = true
+	}
+
+	if fields["camera"] {
+		photo.CameraMake = meta.CameraMake
+		photo.CameraModel = meta.CameraModel
+	}
+	if fields["taken_at"] {
+		photo.TakenAt = meta.TakenAt
+	}
+	if (fields["location"] || publishLocation) && meta.Latitude != nil && meta.Longitude != nil {
+		lat := imaging.CoarseCoordinate(*meta.Latitude)
+		lng := imaging.CoarseCoordinate(*meta.Longitude)
+		photo.Latitude = &lat
+		photo.Longitude = &lng
+		photo.LocationPublic = publishLocation
+	}
+}
+
+// photoURL mengembalikan URL foto, baik dari storage maupun photo_url eksternal
+func photoURL(photo models.Photo) string {
+	if photo.StorageKey == "" {
+		return photo.PhotoURL
+	}
+
+	url, err := storage.Default.URL(photo.StorageKey)
+	if err != nil {
+		log.Printf("failed to build url for %s: %v", photo.StorageKey, err)
+		return ""
+	}
+	return url
+}
+
+// photoVariantURLs memetakan nama variant ke URL-nya
+func photoVariantURLs(photo models.Photo) map[string]string {
+	urls := map[string]string{}
+	for _, variant := range photo.Variants {
+		url, err := storage.Default.URL(variant.StorageKey)
+		if err != nil {
+			log.Printf("failed to build url for %s: %v", variant.StorageKey, err)
+			continue
+		}
+		urls[variant.Name] = url
+	}
+	return urls
+}
+
+// deleteStoredPhoto menghapus file foto beserta variant-nya dari storage.
+// Variant harus sudah dimuat ke photo.Variants.
+func deleteStoredPhoto(photo models.Photo) {
+	keys := []string{}
+	if photo.StorageKey != "" {
+		keys = append(keys, photo.StorageKey)
+	}
+	for _, variant := range photo.Variants {
+		keys = append(keys, variant.StorageKey)
+	}
+
+	for _, key := range keys {
+		if err := storage.Default.Delete(context.Background(), key); err != nil {
+			log.Printf("failed to delete stored photo %s: %v", key, err)
+		}
+	}
+}
+
+func newPhotoResponse(photo models.Photo, likedByMe bool) models.PhotoResponse {
+	return models.PhotoResponse{
+		ID:          photo.ID,
+		Title:       photo.Title,
+		Caption:     photo.Caption,
+		Tags:        hashtag.Parse(photo.Caption),
+		PhotoURL:    photoURL(photo),
+		UserID:      photo.UserID,
+		Variants:    photoVariantURLs(photo),
+		CameraMake:  photo.CameraMake,
+		CameraModel: photo.CameraModel,
+		TakenAt:     photo.TakenAt,
+		Location:    photo.PublicLocation(),
+		LikeCount:   photo.LikeCount,
+		LikedByMe:   likedByMe,
+		Visibility:  photo.Visibility,
+		Status:      photo.Status,
+		PublishedAt: photo.PublishedAt,
+		PublishAt:   photo.PublishAt,
+		CreatedAt:   photo.CreatedAt,
+	}
+}
+
+func maxUploadBytes() int64 {
+	if value, err := strconv.ParseInt(os.Getenv("UPLOAD_MAX_BYTES"), 10, 64); err == nil && value > 0 {
+		return value
+	}
+	return defaultMaxUploadBytes
+}
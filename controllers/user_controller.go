@@ -1,15 +1,22 @@
 package controllers
 
 import (
+	"log"
 	"net/http"
 	"regexp"
 	"time"
 
+	"github.com/Mikael88/go-mygram/auth"
 	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/feed"
+	"github.com/Mikael88/go-mygram/hashtag"
+	"github.com/Mikael88/go-mygram/jobs"
 	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/notifications"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // Register
@@ -28,9 +35,16 @@ func RegisterUser(c *gin.Context) {
     }
 
 	user.UpdateAt = time.Now()
+	user.EmailVerifiedAt = nil
+	user.Role = auth.RoleUser
 
-	if err := config.DB.Create(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err})
+	if err := config.DB.Create(&user).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := sendEmailVerification(user); err != nil {
+		log.Printf("failed to send verification email to user %d: %v", user.ID, err)
 	}
 
 	userResponse := models.UserResponse{
@@ -38,6 +52,9 @@ func RegisterUser(c *gin.Context) {
         Email:    user.Email,
         ID:       user.ID,
         Username: user.Username,
+        Role:     user.Role,
+        EmailVerified: user.IsEmailVerified(),
+        IsPrivate: user.IsPrivate,
     }
 
 	c.JSON(http.StatusCreated, gin.H{"data": userResponse})
@@ -54,24 +71,48 @@ func LoginUser(c *gin.Context) {
 		return
 	}
 
+	// Batasi percobaan login per akun dan per IP
+	attempt := newLoginAttempt(c, input.Email)
+	if !attempt.allowed(c) {
+		return
+	}
+
 	var user models.User
 	if err := config.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		attempt.rejectCredentials(c, "Invalid email or password")
 		return
 	}
 
 	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		attempt.rejectCredentials(c, "Invalid email or password")
+		return
+	}
+	attempt.succeed()
+
+	// User dengan 2FA harus menyelesaikan langkah kedua di /login/2fa
+	if user.IsTwoFactorEnabled() {
+		mfaToken, err := generateMFAToken(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create mfa token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int(mfaTokenTTL.Seconds()),
+		})
 		return
 	}
 
-	token, err := generateJWTToken(user.ID)
+	tokens, err := issueTokens(c, user)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, tokens)
 }
 // Untuk update data user
 func UpdateUser(c *gin.Context) {
@@ -93,22 +134,59 @@ func UpdateUser(c *gin.Context) {
         return
     }
 
+    // Email baru harus diverifikasi ulang
+    emailChanged := req.Email != user.Email
     user.Email = req.Email
-    if req.Password != "" {
-        user.Password = req.Password
-        config.DB.Model(&user).Update("password", user.Password)
+    if emailChanged {
+        user.EmailVerifiedAt = nil
     }
+    // Password hanya dianggap berubah jika berbeda dari password sekarang
+    passwordChanged := req.Password != "" && bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil
+    if passwordChanged {
+        hashedPassword, err := models.HashPassword(req.Password)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+            return
+        }
+        user.Password = hashedPassword
+    }
+
+    err := config.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Save(&user).Error; err != nil {
+            return err
+        }
+        if !passwordChanged {
+            return nil
+        }
 
-    if err := config.DB.Save(&user).Error; err != nil {
+        // Sesi lain dicabut setelah password diganti, sesi yang dipakai tetap aktif
+        revoke := tx.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", user.ID)
+        if sessionId, ok := c.Get("sessionId"); ok {
+            revoke = revoke.Where("id <> ?", sessionId)
+        }
+        if err := revoke.Update("revoked_at", time.Now()).Error; err != nil {
+            return err
+        }
+        return notifySecurity(c, tx, user.ID, notifications.SecurityPasswordChanged)
+    })
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
         return
     }
 
+    if emailChanged {
+        if err := sendEmailVerification(user); err != nil {
+            log.Printf("failed to send verification email to user %d: %v", user.ID, err)
+        }
+        logSecurityEvent(c, user.ID, notifications.SecurityEmailChanged)
+    }
+
     response := models.UpdateUserResponse{
         ID:        user.ID,
         Email:     user.Email,
         Username:  user.Username,
         Age:       user.Age,
+        EmailVerified: user.IsEmailVerified(),
         UpdatedAt: user.UpdateAt,
     }
 
@@ -128,36 +206,133 @@ func DeleteUser(c *gin.Context) {
         return
     }
 
-    // Hapus semua data terkait user
-	if err := config.DB.Where("user_id = ?", userID).Delete(&models.Comment{}).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user's comments"})
+    if err := deleteUserAccount(user); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
         return
     }
 
-	if err := config.DB.Where("user_id = ?", userID).Delete(&models.SocialMedia{}).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user's comments"})
-        return
-    }
+    c.JSON(http.StatusOK, gin.H{"message": "Your account has been successfully deleted"})
+}
+// deleteUserAccount menghapus user beserta semua data terkait dalam satu transaksi
+func deleteUserAccount(user models.User) error {
+	// File foto dihapus dari storage setelah transaksi berhasil
+	var photos []models.Photo
+	if err := config.DB.Preload("Variants").Select("id", "storage_key").Where("user_id = ?", user.ID).Find(&photos).Error; err != nil {
+		return err
+	}
 
-    if err := config.DB.Where("user_id = ?", userID).Delete(&models.Photo{}).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user's photos"})
-        return
-    }
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := removeUserLikes(tx, user.ID); err != nil {
+			return err
+		}
 
-    if err := config.DB.Delete(&user).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
-        return
-    }
+		// Komentar, like, dan variant di foto milik user ikut dihapus bersama fotonya
+		photoIDs := tx.Model(&models.Photo{}).Select("id").Where("user_id = ?", user.ID)
+		commentIDs := tx.Model(&models.Comment{}).Select("id").Where("photo_id IN (?)", photoIDs)
+		if err := deleteTargetLikes(tx, models.LikeTargetComment, commentIDs); err != nil {
+			return err
+		}
+		if err := deleteTargetLikes(tx, models.LikeTargetPhoto, photoIDs); err != nil {
+			return err
+		}
+		if err := hashtag.Unlink(tx, models.TagTargetComment, commentIDs); err != nil {
+			return err
+		}
+		if err := hashtag.Unlink(tx, models.TagTargetPhoto, photoIDs); err != nil {
+			return err
+		}
+		// Penghapusan massal tidak menjalankan hook index pencarian
+		var searchPhotoIDs, searchCommentIDs []uint
+		if err := tx.Model(&models.Photo{}).Where("user_id = ?", user.ID).Pluck("id", &searchPhotoIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Comment{}).Where("photo_id IN (?)", photoIDs).Pluck("id", &searchCommentIDs).Error; err != nil {
+			return err
+		}
+		if err := models.RemoveSearchDocuments(tx, models.SearchKindPhoto, searchPhotoIDs); err != nil {
+			return err
+		}
+		if err := models.RemoveSearchDocuments(tx, models.SearchKindComment, searchCommentIDs); err != nil {
+			return err
+		}
+		if err := tx.Where("photo_id IN (?)", photoIDs).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("photo_id IN (?)", photoIDs).Delete(&models.PhotoVariant{}).Error; err != nil {
+			return err
+		}
+		jobKeys := make([]string, len(photos))
+		for i, photo := range photos {
+			jobKeys[i] = publishPhotoJobKey(photo.ID)
+		}
+		if err := jobs.Cancel(tx, jobKeys...); err != nil {
+			return err
+		}
 
-    c.JSON(http.StatusOK, gin.H{"message": "Your account has been successfully deleted"})
+		if err := feed.Default.UserDeleted(tx, user.ID); err != nil {
+			return err
+		}
+		if err := tx.Where("follower_id = ? OR followee_id = ?", user.ID, user.ID).Delete(&models.Follow{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("requester_id = ? OR account_id = ?", user.ID, user.ID).Delete(&models.AccessRequest{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("blocker_id = ? OR blocked_id = ?", user.ID, user.ID).Delete(&models.Block{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("muter_id = ? OR muted_id = ?", user.ID, user.ID).Delete(&models.Mute{}).Error; err != nil {
+			return err
+		}
+
+		// Balasan orang lain pada komentar user ikut terhapus bersama thread-nya
+		var comments []models.Comment
+		if err := tx.Select("id", "parent_id").Where("user_id = ?", user.ID).Find(&comments).Error; err != nil {
+			return err
+		}
+		if err := deleteCommentThreads(tx, comments); err != nil {
+			return err
+		}
+
+		if err := notifications.DeleteUser(tx, user.ID); err != nil {
+			return err
+		}
+
+		related := []interface{}{
+			&models.SocialMedia{},
+			&models.Photo{},
+			&models.Session{},
+			&models.PasswordReset{},
+			&models.EmailVerification{},
+			&models.RecoveryCode{},
+			&models.PersonalAccessToken{},
+		}
+		for _, model := range related {
+			if err := tx.Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&user).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, photo := range photos {
+		deleteStoredPhoto(photo)
+	}
+	return nil
 }
-// Generate token
-func generateJWTToken(userId uint) (string, error) {
+// Generate access token yang terikat ke sebuah sesi
+func generateJWTToken(userId uint, sessionId uint, role string) (string, error) {
 	claims := jwt.MapClaims{
 		"userId": userId,
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
+		"sid":    sessionId,
+		"role":   role,
+		"typ":    "access",
+		"exp":    time.Now().Add(accessTokenTTL).Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte("your_secret_key"))
+	return auth.Sign(claims)
 }
\ No newline at end of file
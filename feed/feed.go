@@ -0,0 +1,60 @@
+// Package feed menyusun home timeline berisi foto dari akun yang diikuti user.
+//
+// Dua strategi tersedia dan dipilih dengan FEED_STRATEGY:
+//   - read (default): fan-out-on-read, feed dihitung dari tabel follows saat dibaca
+//   - write: fan-out-on-write, setiap foto yang terbit disalin ke timeline_entries
+//     milik semua follower sehingga pembacaan cukup memakai satu index
+package feed
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/models"
+	"gorm.io/gorm"
+)
+
+// Strategy menentukan cara feed dibaca dan dijaga tetap sinkron.
+// Semua hook dipanggil di dalam transaksi perubahan yang memicunya.
+type Strategy interface {
+	// Query membatasi query foto ke feed milik user
+	Query(db *gorm.DB, userID uint) *gorm.DB
+	// SortColumn dan IDColumn adalah kolom urutan feed berdasarkan waktu
+	// publikasi foto (terbaru lebih dulu)
+	SortColumn() string
+	IDColumn() string
+
+	// PhotoPublished dipanggil saat foto menjadi published, baik langsung saat
+	// dibuat maupun dari draft
+	PhotoPublished(tx *gorm.DB, photo models.Photo) error
+	PhotoDeleted(tx *gorm.DB, photoID uint) error
+	Followed(tx *gorm.DB, followerID, followeeID uint) error
+	Unfollowed(tx *gorm.DB, followerID, followeeID uint) error
+	UserDeleted(tx *gorm.DB, userID uint) error
+}
+
+// Default adalah strategi yang dipakai aplikasi, diisi oleh Init.
+var Default Strategy = ReadStrategy{}
+
+// Init memilih strategi feed dari environment FEED_STRATEGY (read atau write).
+// Dengan FEED_REBUILD=true timeline materialisasi dibangun ulang saat start,
+// misalnya setelah berpindah dari strategi read.
+func Init() error {
+	switch strategy := os.Getenv("FEED_STRATEGY"); strategy {
+	case "", "read":
+		Default = ReadStrategy{}
+	case "write":
+		write := WriteStrategy{BackfillLimit: config.EnvIntOrZero("FEED_BACKFILL_LIMIT", defaultBackfillLimit)}
+		if os.Getenv("FEED_REBUILD") == "true" {
+			if err := write.Rebuild(config.DB); err != nil {
+				return err
+			}
+		}
+		Default = write
+	default:
+		return fmt.Errorf("unknown FEED_STRATEGY %q", strategy)
+	}
+
+	return nil
+}
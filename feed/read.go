@@ -0,0 +1,25 @@
+package feed
+
+import (
+	"github.com/Mikael88/go-mygram/models"
+	"gorm.io/gorm"
+)
+
+// ReadStrategy menghitung feed saat dibaca dari foto milik akun yang diikuti.
+// Tidak ada data tambahan yang perlu dijaga, sehingga semua hook kosong.
+type ReadStrategy struct{}
+
+func (ReadStrategy) Query(db *gorm.DB, userID uint) *gorm.DB {
+	followees := db.Session(&gorm.Session{NewDB: true}).
+		Model(&models.Follow{}).Select("followee_id").Where("follower_id = ?", userID)
+	return db.Where("photos.user_id = ? OR photos.user_id IN (?)", userID, followees)
+}
+
+func (ReadStrategy) SortColumn() string { return "photos.published_at" }
+func (ReadStrategy) IDColumn() string   { return "photos.id" }
+
+func (ReadStrategy) PhotoPublished(tx *gorm.DB, photo models.Photo) error      { return nil }
+func (ReadStrategy) PhotoDeleted(tx *gorm.DB, photoID uint) error              { return nil }
+func (ReadStrategy) Followed(tx *gorm.DB, followerID, followeeID uint) error   { return nil }
+func (ReadStrategy) Unfollowed(tx *gorm.DB, followerID, followeeID uint) error { return nil }
+func (ReadStrategy) UserDeleted(tx *gorm.DB, userID uint) error                { return nil }
@@ -0,0 +1,78 @@
+package feed
+
+import (
+	"github.com/Mikael88/go-mygram/models"
+	"gorm.io/gorm"
+)
+
+// defaultBackfillLimit adalah jumlah foto terbaru yang disalin ke timeline
+// saat user mulai mengikuti akun lain
+const defaultBackfillLimit = 200
+
+// WriteStrategy menyalin setiap foto ke timeline_entries milik pemilik foto dan
+// semua follower-nya saat foto terbit.
+type WriteStrategy struct {
+	BackfillLimit int
+}
+
+func (WriteStrategy) Query(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Joins("JOIN timeline_entries ON timeline_entries.photo_id = photos.id").
+		Where("timeline_entries.user_id = ?", userID)
+}
+
+func (WriteStrategy) SortColumn() string { return "timeline_entries.created_at" }
+func (WriteStrategy) IDColumn() string   { return "timeline_entries.photo_id" }
+
+func (WriteStrategy) PhotoPublished(tx *gorm.DB, photo models.Photo) error {
+	publishedAt := photo.PublishedTime()
+	return tx.Exec(
+		`INSERT IGNORE INTO timeline_entries (user_id, photo_id, created_at)
+		SELECT follower_id, ?, ? FROM follows WHERE followee_id = ?
+		UNION ALL SELECT ?, ?, ?`,
+		photo.ID, publishedAt, photo.UserID,
+		photo.UserID, photo.ID, publishedAt,
+	).Error
+}
+
+func (WriteStrategy) PhotoDeleted(tx *gorm.DB, photoID uint) error {
+	return tx.Where("photo_id = ?", photoID).Delete(&models.TimelineEntry{}).Error
+}
+
+// Followed mengisi timeline follower dengan foto terbaru akun yang baru diikuti
+func (s WriteStrategy) Followed(tx *gorm.DB, followerID, followeeID uint) error {
+	return tx.Exec(
+		`INSERT IGNORE INTO timeline_entries (user_id, photo_id, created_at)
+		SELECT ?, id, published_at FROM photos WHERE user_id = ? AND status = ?
+		ORDER BY published_at DESC LIMIT ?`,
+		followerID, followeeID, models.PhotoPublished, s.BackfillLimit,
+	).Error
+}
+
+func (WriteStrategy) Unfollowed(tx *gorm.DB, followerID, followeeID uint) error {
+	photos := tx.Session(&gorm.Session{NewDB: true}).
+		Model(&models.Photo{}).Select("id").Where("user_id = ?", followeeID)
+	return tx.Where("user_id = ? AND photo_id IN (?)", followerID, photos).Delete(&models.TimelineEntry{}).Error
+}
+
+func (WriteStrategy) UserDeleted(tx *gorm.DB, userID uint) error {
+	photos := tx.Session(&gorm.Session{NewDB: true}).
+		Model(&models.Photo{}).Select("id").Where("user_id = ?", userID)
+	return tx.Where("user_id = ? OR photo_id IN (?)", userID, photos).Delete(&models.TimelineEntry{}).Error
+}
+
+// Rebuild membangun ulang seluruh timeline dari tabel follows dan photos
+func (WriteStrategy) Rebuild(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM timeline_entries").Error; err != nil {
+			return err
+		}
+		return tx.Exec(
+			`INSERT IGNORE INTO timeline_entries (user_id, photo_id, created_at)
+			SELECT follows.follower_id, photos.id, photos.published_at
+			FROM follows JOIN photos ON photos.user_id = follows.followee_id
+			WHERE photos.status = ?
+			UNION ALL SELECT user_id, id, published_at FROM photos WHERE status = ?`,
+			models.PhotoPublished, models.PhotoPublished,
+		).Error
+	})
+}
@@ -0,0 +1,96 @@
+// Package hashtag mengekstrak #hashtag dari teks caption dan komentar serta
+// menyimpan relasinya ke tabel tags dan tag_links.
+//
+// Hashtag dikenali di semua bahasa: setelah tanda # boleh ada huruf, angka,
+// tanda diakritik, dan garis bawah dari skrip apa pun, dengan minimal satu
+// huruf. Tag disimpan dalam bentuk ternormalisasi (NFC lalu case folding),
+// sehingga #Café, #CAFÉ, dan #café dianggap tag yang sama.
+package hashtag
+
+import (
+	"unicode"
+
+	"github.com/Mikael88/go-mygram/models"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength adalah panjang maksimal hashtag dalam rune, tanpa tanda #
+const MaxLength = 100
+
+// Parse mengembalikan semua hashtag di dalam teks sesuai urutan kemunculannya
+func Parse(text string) []models.TagEntity {
+	runes := []rune(text)
+	entities := []models.TagEntity{}
+
+	for i := 0; i < len(runes); i++ {
+		if !isMarker(runes[i]) || (i > 0 && !isBoundary(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		hasLetter := false
+		for end < len(runes) && isTagRune(runes[end]) {
+			hasLetter = hasLetter || unicode.IsLetter(runes[end])
+			end++
+		}
+
+		// Tag yang langsung diikuti # lain, misalnya #a#b, bukan hashtag
+		if end < len(runes) && isMarker(runes[end]) {
+			i = end
+			continue
+		}
+
+		body := string(runes[i+1 : end])
+		if hasLetter && end-i-1 <= MaxLength {
+			entities = append(entities, models.TagEntity{Tag: Normalize(body), Text: body, Start: i, End: end})
+		}
+		i = end - 1
+	}
+	return entities
+}
+
+// Tags mengembalikan tag ternormalisasi yang unik di dalam teks
+func Tags(text string) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, entity := range Parse(text) {
+		if !seen[entity.Tag] {
+			seen[entity.Tag] = true
+			tags = append(tags, entity.Tag)
+		}
+	}
+	return tags
+}
+
+// Normalize mengubah tag ke bentuk yang disimpan. Tanda # di depan diabaikan.
+func Normalize(tag string) string {
+	runes := []rune(tag)
+	if len(runes) > 0 && isMarker(runes[0]) {
+		tag = string(runes[1:])
+	}
+	return norm.NFC.String(cases.Fold().String(norm.NFC.String(tag)))
+}
+
+// Valid mengembalikan true jika tag ternormalisasi bisa menjadi hashtag
+func Valid(tag string) bool {
+	entities := Parse("#" + tag)
+	return len(entities) == 1 && entities[0].Tag == tag
+}
+
+func isMarker(r rune) bool {
+	return r == '#' || r == '\uff03'
+}
+
+// isTagRune mengembalikan true untuk karakter yang boleh ada di dalam tag.
+// Zero width joiner dan non-joiner dibutuhkan beberapa skrip seperti Persia dan Devanagari.
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_' || r == '\u200c' || r == '\u200d'
+}
+
+// isBoundary mengembalikan true jika karakter sebelum # memisahkan hashtag
+// dari kata sebelumnya. Tanda & menghindari entitas HTML seperti &#39;.
+func isBoundary(r rune) bool {
+	return !isTagRune(r) && r != '&'
+}
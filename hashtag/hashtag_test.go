@@ -0,0 +1,110 @@
+package hashtag
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Mikael88/go-mygram/models"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []models.TagEntity
+	}{
+		{"simple", "hello #world", []models.TagEntity{{Tag: "world", Text: "world", Start: 6, End: 12}}},
+		{"start of text", "#go is fun", []models.TagEntity{{Tag: "go", Text: "go", Start: 0, End: 3}}},
+		{"case folded", "#GoLang", []models.TagEntity{{Tag: "golang", Text: "GoLang", Start: 0, End: 7}}},
+		{"multiple", "#a #b", []models.TagEntity{{Tag: "a", Text: "a", Start: 0, End: 2}, {Tag: "b", Text: "b", Start: 3, End: 5}}},
+		{"trailing punctuation", "(#tag), #end.", []models.TagEntity{{Tag: "tag", Text: "tag", Start: 1, End: 5}, {Tag: "end", Text: "end", Start: 8, End: 12}}},
+		{"underscore and digits", "#new_york2024", []models.TagEntity{{Tag: "new_york2024", Text: "new_york2024", Start: 0, End: 13}}},
+		{"digits need a letter", "#2024 #1st", []models.TagEntity{{Tag: "1st", Text: "1st", Start: 6, End: 10}}},
+		{"only underscore", "#_", []models.TagEntity{}},
+		{"empty tag", "# #", []models.TagEntity{}},
+		{"inside word", "café#tag", []models.TagEntity{}},
+		{"after digit", "1#tag", []models.TagEntity{}},
+		{"after underscore", "_#tag", []models.TagEntity{}},
+		{"after punctuation", "x.#tag", []models.TagEntity{{Tag: "tag", Text: "tag", Start: 2, End: 6}}},
+		{"html entity", "it&#39;s &#x27;", []models.TagEntity{}},
+		{"ampersand word", "rock&#roll", []models.TagEntity{}},
+		{"chained", "#a#b", []models.TagEntity{}},
+		{"chained then valid", "#a#b #c", []models.TagEntity{{Tag: "c", Text: "c", Start: 5, End: 7}}},
+		{"double marker", "##tag", []models.TagEntity{}},
+		{"url fragment", "see http://x.com/page#section", []models.TagEntity{}},
+		{"accented", "#Café", []models.TagEntity{{Tag: "café", Text: "Café", Start: 0, End: 5}}},
+		{"decomposed accent", "#Cafe\u0301", []models.TagEntity{{Tag: "café", Text: "Cafe\u0301", Start: 0, End: 6}}},
+		{"japanese", "東京 #東京タワー", []models.TagEntity{{Tag: "東京タワー", Text: "東京タワー", Start: 3, End: 9}}},
+		{"japanese no space", "東京#東京", []models.TagEntity{}},
+		{"devanagari marks", "#हिन्दी", []models.TagEntity{{Tag: "हिन्दी", Text: "हिन्दी", Start: 0, End: 7}}},
+		{"persian zwnj", "#می\u200cخواهم", []models.TagEntity{{Tag: "می\u200cخواهم", Text: "می\u200cخواهم", Start: 0, End: 9}}},
+		{"full-width marker", "＃ｔａｇ", []models.TagEntity{{Tag: "ｔａｇ", Text: "ｔａｇ", Start: 0, End: 4}}},
+		{"sharp s folded", "#STRASSE #Straße", []models.TagEntity{{Tag: "strasse", Text: "STRASSE", Start: 0, End: 8}, {Tag: "strasse", Text: "Straße", Start: 9, End: 16}}},
+		{"emoji ends tag", "#sun☀️", []models.TagEntity{{Tag: "sun", Text: "sun", Start: 0, End: 4}}},
+		{"max length", "#" + strings.Repeat("a", MaxLength), []models.TagEntity{{Tag: strings.Repeat("a", MaxLength), Text: strings.Repeat("a", MaxLength), Start: 0, End: MaxLength + 1}}},
+		{"too long", "#" + strings.Repeat("a", MaxLength+1), []models.TagEntity{}},
+		{"empty text", "", []models.TagEntity{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// Offset adalah offset rune sehingga bisa dipakai untuk memotong teks
+func TestParseOffsetsMatchText(t *testing.T) {
+	text := "Liburan 🏖️ di #Bali dan #東京, lalu #Café!"
+	runes := []rune(text)
+	for _, entity := range Parse(text) {
+		if got := string(runes[entity.Start:entity.End]); got != "#"+entity.Text {
+			t.Errorf("runes[%d:%d] = %q, want %q", entity.Start, entity.End, got, "#"+entity.Text)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	got := Tags("#Go #go #GO #rust #Go")
+	if want := []string{"go", "rust"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tags = %v, want %v", got, want)
+	}
+	if got := Tags("no tags here"); len(got) != 0 {
+		t.Errorf("Tags without hashtags = %v", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"#Café":       "café",
+		"CAFE\u0301":  "café",
+		"＃Tag":        "tag",
+		"Straße":      "strasse",
+		"already":     "already",
+		"#":           "",
+		"cafe":        "cafe",
+		"ΣΊΣΥΦΟΣ":     "σίσυφοσ",
+		"#MixedCASE1": "mixedcase1",
+	}
+	for in, want := range tests {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	for _, tag := range []string{"go", "café", "東京", "new_york", "1st"} {
+		if !Valid(tag) {
+			t.Errorf("Valid(%q) = false", tag)
+		}
+	}
+	for _, tag := range []string{"", "Go", "123", "two words", "a#b", "#go", strings.Repeat("a", MaxLength+1)} {
+		if Valid(tag) {
+			t.Errorf("Valid(%q) = true", tag)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package hashtag
+
+import (
+	"time"
+
+	"github.com/Mikael88/go-mygram/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sync menyamakan tag_links sebuah foto atau komentar dengan hashtag di
+// dalam teksnya. Dipanggil di dalam transaksi yang menyimpan konten tersebut.
+// Link yang tetap ada tidak diubah sehingga waktu pembuatannya terjaga.
+func Sync(tx *gorm.DB, targetType string, targetID, userID uint, text string) error {
+	return sync(tx, targetType, targetID, userID, text, time.Time{})
+}
+
+// sync adalah Sync dengan waktu pembuatan link baru. Waktu nol berarti sekarang.
+func sync(tx *gorm.DB, targetType string, targetID, userID uint, text string, linkedAt time.Time) error {
+	names := Tags(text)
+	if len(names) == 0 {
+		return Unlink(tx, targetType, []uint{targetID})
+	}
+
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = models.Tag{Name: name}
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return err
+	}
+
+	var tagIDs []uint
+	if err := tx.Model(&models.Tag{}).Where("name IN ?", names).Pluck("id", &tagIDs).Error; err != nil {
+		return err
+	}
+
+	err := tx.Where("target_type = ? AND target_id = ? AND tag_id NOT IN ?", targetType, targetID, tagIDs).
+		Delete(&models.TagLink{}).Error
+	if err != nil {
+		return err
+	}
+
+	links := make([]models.TagLink, len(tagIDs))
+	for i, tagID := range tagIDs {
+		links[i] = models.TagLink{TagID: tagID, TargetType: targetType, TargetID: targetID, UserID: userID, CreatedAt: linkedAt}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// Unlink menghapus semua tag_links milik konten yang dihapus. ids boleh
+// berupa slice ID atau subquery.
+func Unlink(tx *gorm.DB, targetType string, ids interface{}) error {
+	return tx.Where("target_type = ? AND target_id IN (?)", targetType, ids).Delete(&models.TagLink{}).Error
+}
+
+// Backfill membuat tag_links untuk foto dan komentar yang ditulis sebelum
+// hashtag didukung. Dijalankan sekali sebagai migrasi data; hanya konten yang
+// memuat tanda # dan belum punya link yang diproses, sehingga aman diulang jika
+// sempat terhenti di tengah jalan. Link memakai
+// waktu pembuatan konten agar konten lama tidak ikut dihitung sebagai trending.
+func Backfill(db *gorm.DB, batchSize int) error {
+	var photos []models.Photo
+	err := unlinked(db, &models.Photo{}, models.TagTargetPhoto, "photos", "caption").
+		Select("id", "user_id", "caption", "created_at").
+		FindInBatches(&photos, batchSize, func(_ *gorm.DB, _ int) error {
+			return db.Transaction(func(tx *gorm.DB) error {
+				for _, photo := range photos {
+					if err := sync(tx, models.TagTargetPhoto, photo.ID, photo.UserID, photo.Caption, photo.CreatedAt); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	var comments []models.Comment
+	return unlinked(db, &models.Comment{}, models.TagTargetComment, "comments", "message").
+		Select("id", "user_id", "message", "created_at").
+		FindInBatches(&comments, batchSize, func(_ *gorm.DB, _ int) error {
+			return db.Transaction(func(tx *gorm.DB) error {
+				for _, comment := range comments {
+					if err := sync(tx, models.TagTargetComment, comment.ID, comment.UserID, comment.Message, comment.CreatedAt); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}).Error
+}
+
+// unlinked memilih konten yang teksnya memuat tanda # tetapi belum punya tag_links
+func unlinked(db *gorm.DB, model interface{}, targetType, table, column string) *gorm.DB {
+	return db.Model(model).
+		Where(column+" LIKE ? OR "+column+" LIKE ?", "%#%", "%\uff03%").
+		Where("NOT EXISTS (SELECT 1 FROM tag_links WHERE tag_links.target_type = ? AND tag_links.target_id = "+table+".id)", targetType)
+}
@@ -0,0 +1,208 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// Metadata berisi field EXIF yang bisa disimpan dari foto.
+type Metadata struct {
+	CameraMake  string
+	CameraModel string
+	TakenAt     *time.Time
+	Latitude    *float64
+	Longitude   *float64
+	Orientation int
+}
+
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagGPSLatitudeRef   = 0x0001
+	tagGPSLatitude      = 0x0002
+	tagGPSLongitudeRef  = 0x0003
+	tagGPSLongitude     = 0x0004
+)
+
+var errInvalidTIFF = errors.New("imaging: invalid EXIF data")
+
+// tiffEntry adalah satu entry IFD beserta data mentahnya
+type tiffEntry struct {
+	typ   uint16
+	count uint32
+	data  []byte
+}
+
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// parseEXIF membaca field yang dibutuhkan dari blok TIFF EXIF
+func parseEXIF(data []byte) (Metadata, error) {
+	var meta Metadata
+	if len(data) < 8 {
+		return meta, errInvalidTIFF
+	}
+
+	r := tiffReader{data: data}
+	switch string(data[:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return meta, errInvalidTIFF
+	}
+
+	ifd0, err := r.readIFD(r.order.Uint32(data[4:8]))
+	if err != nil {
+		return meta, err
+	}
+
+	meta.CameraMake = r.ascii(ifd0[tagMake])
+	meta.CameraModel = r.ascii(ifd0[tagModel])
+	if v, ok := r.uint(ifd0[tagOrientation]); ok {
+		meta.Orientation = int(v)
+	}
+
+	if offset, ok := r.uint(ifd0[tagExifIFD]); ok {
+		if exifIFD, err := r.readIFD(offset); err == nil {
+			if t, err := time.ParseInLocation("2006:01:02 15:04:05", r.ascii(exifIFD[tagDateTimeOriginal]), time.UTC); err == nil {
+				meta.TakenAt = &t
+			}
+		}
+	}
+
+	if offset, ok := r.uint(ifd0[tagGPSIFD]); ok {
+		if gpsIFD, err := r.readIFD(offset); err == nil {
+			meta.Latitude = r.coordinate(gpsIFD[tagGPSLatitude], r.ascii(gpsIFD[tagGPSLatitudeRef]), "S")
+			meta.Longitude = r.coordinate(gpsIFD[tagGPSLongitude], r.ascii(gpsIFD[tagGPSLongitudeRef]), "W")
+		}
+	}
+
+	return meta, nil
+}
+
+func (r tiffReader) readIFD(offset uint32) (map[uint16]tiffEntry, error) {
+	if uint64(offset)+2 > uint64(len(r.data)) {
+		return nil, errInvalidTIFF
+	}
+
+	count := int(r.order.Uint16(r.data[offset:]))
+	entries := make(map[uint16]tiffEntry, count)
+	for i := 0; i < count; i++ {
+		pos := uint64(offset) + 2 + uint64(i)*12
+		if pos+12 > uint64(len(r.data)) {
+			return nil, errInvalidTIFF
+		}
+		raw := r.data[pos : pos+12]
+
+		typ := r.order.Uint16(raw[2:])
+		n := r.order.Uint32(raw[4:])
+		size := uint64(typeSize(typ)) * uint64(n)
+		if size == 0 {
+			continue
+		}
+
+		// Nilai <= 4 byte disimpan langsung di entry, selebihnya lewat offset
+		var value []byte
+		if size <= 4 {
+			value = raw[8 : 8+size]
+		} else {
+			valueOffset := uint64(r.order.Uint32(raw[8:]))
+			if valueOffset+size > uint64(len(r.data)) {
+				continue
+			}
+			value = r.data[valueOffset : valueOffset+size]
+		}
+
+		entries[r.order.Uint16(raw)] = tiffEntry{typ: typ, count: n, data: value}
+	}
+	return entries, nil
+}
+
+func (r tiffReader) ascii(e tiffEntry) string {
+	if e.typ != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(e.data), "\x00"))
+}
+
+func (r tiffReader) uint(e tiffEntry) (uint32, bool) {
+	switch {
+	case e.typ == 3 && len(e.data) >= 2:
+		return uint32(r.order.Uint16(e.data)), true
+	case e.typ == 4 && len(e.data) >= 4:
+		return r.order.Uint32(e.data), true
+	}
+	return 0, false
+}
+
+// coordinate mengubah derajat, menit, detik (3 RATIONAL) menjadi derajat desimal
+func (r tiffReader) coordinate(e tiffEntry, ref, negativeRef string) *float64 {
+	if e.typ != 5 || len(e.data) < 24 {
+		return nil
+	}
+
+	var parts [3]float64
+	for i := range parts {
+		num := r.order.Uint32(e.data[i*8:])
+		den := r.order.Uint32(e.data[i*8+4:])
+		if den == 0 {
+			return nil
+		}
+		parts[i] = float64(num) / float64(den)
+	}
+
+	value := parts[0] + parts[1]/60 + parts[2]/3600
+	if ref == negativeRef {
+		value = -value
+	}
+	if math.IsNaN(value) || math.Abs(value) > 180 {
+		return nil
+	}
+	return &value
+}
+
+func typeSize(typ uint16) int {
+	switch typ {
+	case 1, 2, 6, 7:
+		return 1
+	case 3, 8:
+		return 2
+	case 4, 9, 11:
+		return 4
+	case 5, 10, 12:
+		return 8
+	}
+	return 0
+}
+
+// orientationEXIF membuat blok TIFF minimal yang hanya berisi tag Orientation,
+// agar foto tetap tampil dengan rotasi yang benar setelah EXIF dihapus
+func orientationEXIF(orientation int) []byte {
+	b := make([]byte, 0, 26)
+	b = append(b, 'M', 'M', 0, 42, 0, 0, 0, 8)
+	b = binary.BigEndian.AppendUint16(b, 1)
+	b = binary.BigEndian.AppendUint16(b, tagOrientation)
+	b = binary.BigEndian.AppendUint16(b, 3)
+	b = binary.BigEndian.AppendUint32(b, 1)
+	b = binary.BigEndian.AppendUint16(b, uint16(orientation))
+	b = append(b, 0, 0)
+	b = binary.BigEndian.AppendUint32(b, 0)
+	return b
+}
+
+// CoarseCoordinate membulatkan koordinat ke satu desimal (sekitar 11 km)
+// sehingga tidak bisa dipakai untuk menemukan lokasi yang persis.
+func CoarseCoordinate(value float64) float64 {
+	return math.Round(value*10) / 10
+}
@@ -0,0 +1,210 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var errMalformedImage = errors.New("imaging: malformed image data")
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// StripMetadata menghapus metadata EXIF, XMP, IPTC, dan komentar dari gambar
+// JPEG, PNG, dan WebP, lalu mengembalikan gambar bersih beserta field EXIF yang
+// sempat dibaca. Tag Orientation JPEG dipertahankan agar rotasi tetap benar.
+// Format lain dikembalikan apa adanya.
+func StripMetadata(data []byte, contentType string) ([]byte, Metadata, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	}
+	return data, Metadata{}, nil
+}
+
+func stripJPEG(data []byte) ([]byte, Metadata, error) {
+	var meta Metadata
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, meta, errMalformedImage
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+
+	pos := 2
+	orientationWritten := false
+	for {
+		if pos+4 > len(data) || data[pos] != 0xFF {
+			return nil, meta, errMalformedImage
+		}
+
+		marker := data[pos+1]
+		if marker == 0xFF {
+			// Byte pengisi
+			pos++
+			continue
+		}
+
+		// Start of scan: sisa file adalah data gambar
+		if marker == 0xDA {
+			if meta.Orientation > 1 && !orientationWritten {
+				writeJPEGOrientation(out, meta.Orientation)
+			}
+			out.Write(data[pos:])
+			return out.Bytes(), meta, nil
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, meta, errMalformedImage
+		}
+		segment := data[pos:end]
+		payload := data[pos+4 : end]
+
+		switch {
+		case marker == 0xE1:
+			// APP1 berisi EXIF atau XMP
+			if bytes.HasPrefix(payload, exifHeader) {
+				if parsed, err := parseEXIF(payload[len(exifHeader):]); err == nil {
+					meta = parsed
+				}
+			}
+		case marker == 0xED, marker == 0xFE:
+			// APP13 (IPTC/Photoshop) dan komentar
+		default:
+			// Frame header pertama: tulis orientasi sebelum data gambar
+			if meta.Orientation > 1 && !orientationWritten && marker != 0xE0 {
+				writeJPEGOrientation(out, meta.Orientation)
+				orientationWritten = true
+			}
+			out.Write(segment)
+		}
+
+		pos = end
+	}
+}
+
+func writeJPEGOrientation(out *bytes.Buffer, orientation int) {
+	payload := append(append([]byte{}, exifHeader...), orientationEXIF(orientation)...)
+	out.Write([]byte{0xFF, 0xE1})
+	binary.Write(out, binary.BigEndian, uint16(len(payload)+2))
+	out.Write(payload)
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngMetadataChunks adalah chunk PNG yang dibuang
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+func stripPNG(data []byte) ([]byte, Metadata, error) {
+	var meta Metadata
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, meta, errMalformedImage
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, meta, errMalformedImage
+		}
+
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		chunkType := string(data[pos+4 : pos+8])
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, meta, errMalformedImage
+		}
+
+		if chunkType == "eXIf" {
+			if parsed, err := parseEXIF(data[pos+8 : pos+8+length]); err == nil {
+				meta = parsed
+			}
+		}
+		if !pngMetadataChunks[chunkType] {
+			out.Write(data[pos:end])
+		}
+
+		pos = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+
+	return out.Bytes(), meta, nil
+}
+
+const (
+	webpFlagXMP  = 0x04
+	webpFlagEXIF = 0x08
+)
+
+func stripWebP(data []byte) ([]byte, Metadata, error) {
+	var meta Metadata
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, meta, errMalformedImage
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12])
+
+	pos := 12
+	for pos+8 <= len(data) {
+		fourCC := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2
+		if size < 0 || pos+8+size > len(data) {
+			return nil, meta, errMalformedImage
+		}
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := data[pos:end]
+
+		switch fourCC {
+		case "EXIF":
+			exif := bytes.TrimPrefix(data[pos+8:pos+8+size], exifHeader)
+			if parsed, err := parseEXIF(exif); err == nil {
+				meta = parsed
+			}
+		case "XMP ":
+		case "VP8X":
+			// Hapus flag EXIF dan XMP di header extended
+			vp8x := append([]byte{}, chunk...)
+			if len(vp8x) > 8 {
+				vp8x[8] &^= webpFlagEXIF | webpFlagXMP
+			}
+			out.Write(vp8x)
+		default:
+			out.Write(chunk)
+		}
+
+		pos = end
+	}
+
+	result := out.Bytes()
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+	return result, meta, nil
+}
+
+// ExtractOrientation membaca tag Orientation EXIF dari JPEG, bernilai 1 jika tidak ada.
+func ExtractOrientation(data []byte) int {
+	_, meta, err := stripJPEG(data)
+	if err != nil || meta.Orientation == 0 {
+		return 1
+	}
+	return meta.Orientation
+}
@@ -0,0 +1,311 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testEXIF membuat blok TIFF big-endian berisi Make, Orientation,
+// DateTimeOriginal, dan koordinat GPS (6°10'30" S, 106°49'12" E)
+func testEXIF(orientation uint16) []byte {
+	const (
+		ifd0Offset = 8
+		makeOffset = ifd0Offset + 2 + 4*12 + 4
+		exifOffset = makeOffset + 6
+		dateOffset = exifOffset + 2 + 12 + 4
+		gpsOffset  = dateOffset + 20
+		latOffset  = gpsOffset + 2 + 4*12 + 4
+		lonOffset  = latOffset + 24
+	)
+
+	b := []byte{'M', 'M', 0, 42, 0, 0, 0, ifd0Offset}
+	entry := func(tag, typ uint16, count, value uint32) {
+		b = binary.BigEndian.AppendUint16(b, tag)
+		b = binary.BigEndian.AppendUint16(b, typ)
+		b = binary.BigEndian.AppendUint32(b, count)
+		b = binary.BigEndian.AppendUint32(b, value)
+	}
+	rational := func(values ...uint32) {
+		for i := 0; i < len(values); i += 2 {
+			b = binary.BigEndian.AppendUint32(b, values[i])
+			b = binary.BigEndian.AppendUint32(b, values[i+1])
+		}
+	}
+
+	b = binary.BigEndian.AppendUint16(b, 4)
+	entry(tagMake, 2, 6, makeOffset)
+	entry(tagOrientation, 3, 1, uint32(orientation)<<16)
+	entry(tagExifIFD, 4, 1, exifOffset)
+	entry(tagGPSIFD, 4, 1, gpsOffset)
+	b = binary.BigEndian.AppendUint32(b, 0)
+	b = append(b, "Canon\x00"...)
+
+	b = binary.BigEndian.AppendUint16(b, 1)
+	entry(tagDateTimeOriginal, 2, 20, dateOffset)
+	b = binary.BigEndian.AppendUint32(b, 0)
+	b = append(b, "2024:01:02 03:04:05\x00"...)
+
+	b = binary.BigEndian.AppendUint16(b, 4)
+	entry(tagGPSLatitudeRef, 2, 2, uint32('S')<<24)
+	entry(tagGPSLatitude, 5, 3, latOffset)
+	entry(tagGPSLongitudeRef, 2, 2, uint32('E')<<24)
+	entry(tagGPSLongitude, 5, 3, lonOffset)
+	b = binary.BigEndian.AppendUint32(b, 0)
+	rational(6, 1, 10, 1, 30, 1)
+	rational(106, 1, 49, 1, 12, 1)
+	return b
+}
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	for x := 0; x < 8; x++ {
+		img.Set(x, x%4, color.RGBA{R: 200, A: 255})
+	}
+	return img
+}
+
+func jpegSegment(marker byte, payload []byte) []byte {
+	b := []byte{0xFF, marker}
+	b = binary.BigEndian.AppendUint16(b, uint16(len(payload)+2))
+	return append(b, payload...)
+}
+
+// testJPEG menyisipkan segmen metadata setelah SOI dari JPEG yang valid
+func testJPEG(t *testing.T, orientation uint16) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+
+	var b []byte
+	b = append(b, encoded[:2]...)
+	b = append(b, jpegSegment(0xE0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))...)
+	b = append(b, jpegSegment(0xE1, append(append([]byte{}, exifHeader...), testEXIF(orientation)...))...)
+	b = append(b, jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>secret place</x:xmpmeta>"))...)
+	b = append(b, jpegSegment(0xFE, []byte("taken at home"))...)
+	b = append(b, jpegSegment(0xED, []byte("Photoshop 3.0\x00IPTC city"))...)
+	return append(b, encoded[2:]...)
+}
+
+func TestStripJPEG(t *testing.T) {
+	data := testJPEG(t, 6)
+
+	out, meta, err := StripMetadata(data, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if meta.CameraMake != "Canon" || meta.Orientation != 6 {
+		t.Errorf("meta = %+v", meta)
+	}
+	if meta.TakenAt == nil || meta.TakenAt.Format("2006-01-02 15:04:05") != "2024-01-02 03:04:05" {
+		t.Errorf("TakenAt = %v", meta.TakenAt)
+	}
+	if meta.Latitude == nil || meta.Longitude == nil || *meta.Latitude > -6.17 || *meta.Latitude < -6.18 || *meta.Longitude < 106.81 || *meta.Longitude > 106.83 {
+		t.Errorf("coordinates = %v, %v", meta.Latitude, meta.Longitude)
+	}
+
+	for _, leaked := range []string{"Canon", "2024:01:02", "xmpmeta", "taken at home", "Photoshop"} {
+		if bytes.Contains(out, []byte(leaked)) {
+			t.Errorf("stripped JPEG still contains %q", leaked)
+		}
+	}
+	if !bytes.Contains(out, []byte("JFIF")) {
+		t.Error("APP0 JFIF segment was removed")
+	}
+
+	// Hanya Orientation yang tersisa di EXIF hasil
+	_, kept, err := stripJPEG(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept.Orientation != 6 || kept.Latitude != nil || kept.Longitude != nil || kept.CameraMake != "" || kept.TakenAt != nil {
+		t.Errorf("metadata after strip = %+v", kept)
+	}
+	if got := ExtractOrientation(out); got != 6 {
+		t.Errorf("ExtractOrientation = %d, want 6", got)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("stripped JPEG does not decode: %v", err)
+	}
+	if img.Bounds() != testImage().Bounds() {
+		t.Errorf("bounds = %v", img.Bounds())
+	}
+}
+
+func TestStripJPEGNormalOrientation(t *testing.T) {
+	out, _, err := StripMetadata(testJPEG(t, 1), "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, exifHeader) {
+		t.Error("EXIF segment written for orientation 1")
+	}
+	if got := ExtractOrientation(out); got != 1 {
+		t.Errorf("ExtractOrientation = %d, want 1", got)
+	}
+}
+
+func pngChunk(chunkType string, payload []byte) []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	b = append(b, chunkType...)
+	b = append(b, payload...)
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(b[4:]))
+}
+
+func testPNG(t *testing.T) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage()); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+
+	// Signature (8) + IHDR (25), lalu chunk metadata sebelum IDAT
+	ihdrEnd := len(pngSignature) + 25
+	var b []byte
+	b = append(b, encoded[:ihdrEnd]...)
+	b = append(b, pngChunk("tEXt", []byte("Comment\x00taken at home"))...)
+	b = append(b, pngChunk("eXIf", testEXIF(1))...)
+	b = append(b, pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00<x:xmpmeta/>"))...)
+	b = append(b, pngChunk("tIME", []byte{0x07, 0xE8, 1, 2, 3, 4, 5})...)
+	return append(b, encoded[ihdrEnd:]...)
+}
+
+func TestStripPNG(t *testing.T) {
+	out, meta, err := StripMetadata(testPNG(t), "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if meta.CameraMake != "Canon" || meta.Latitude == nil {
+		t.Errorf("meta = %+v", meta)
+	}
+	for _, chunk := range []string{"tEXt", "eXIf", "iTXt", "tIME", "Canon", "taken at home"} {
+		if bytes.Contains(out, []byte(chunk)) {
+			t.Errorf("stripped PNG still contains %q", chunk)
+		}
+	}
+	if _, err := png.Decode(bytes.NewReader(out)); err != nil {
+		t.Fatalf("stripped PNG does not decode: %v", err)
+	}
+}
+
+func webpChunk(fourCC string, payload []byte) []byte {
+	b := append([]byte(fourCC), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+	b = append(b, payload...)
+	if len(payload)%2 == 1 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func testWebP() []byte {
+	vp8x := make([]byte, 10)
+	vp8x[0] = webpFlagEXIF | webpFlagXMP
+	body := []byte("WEBP")
+	body = append(body, webpChunk("VP8X", vp8x)...)
+	body = append(body, webpChunk("VP8L", []byte("image-data"))...)
+	body = append(body, webpChunk("EXIF", append(append([]byte{}, exifHeader...), testEXIF(1)...))...)
+	body = append(body, webpChunk("XMP ", []byte("<x:xmpmeta>secret place</x:xmpmeta>!"))...)
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+}
+
+func TestStripWebP(t *testing.T) {
+	out, meta, err := StripMetadata(testWebP(), "image/webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if meta.CameraMake != "Canon" || meta.Longitude == nil {
+		t.Errorf("meta = %+v", meta)
+	}
+	for _, leaked := range []string{"EXIF", "XMP ", "Canon", "secret place"} {
+		if bytes.Contains(out, []byte(leaked)) {
+			t.Errorf("stripped WebP still contains %q", leaked)
+		}
+	}
+	if !bytes.Contains(out, []byte("image-data")) {
+		t.Error("image chunk was removed")
+	}
+	if flags := out[20]; flags&(webpFlagEXIF|webpFlagXMP) != 0 {
+		t.Errorf("VP8X flags = %#x, want EXIF and XMP cleared", flags)
+	}
+	if size := binary.LittleEndian.Uint32(out[4:]); int(size) != len(out)-8 {
+		t.Errorf("RIFF size = %d, want %d", size, len(out)-8)
+	}
+}
+
+func TestStripMetadataUnknownType(t *testing.T) {
+	data := []byte("GIF89a...")
+	out, _, err := StripMetadata(data, "image/gif")
+	if err != nil || !bytes.Equal(out, data) {
+		t.Errorf("StripMetadata(gif) = %q, %v", out, err)
+	}
+}
+
+func TestStripMalformed(t *testing.T) {
+	jpegData := testJPEG(t, 6)
+	pngData := testPNG(t)
+	webpData := testWebP()
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+	}{
+		{"empty jpeg", "image/jpeg", nil},
+		{"not a jpeg", "image/jpeg", []byte("hello world")},
+		{"jpeg cut inside exif", "image/jpeg", jpegData[:40]},
+		{"jpeg cut before scan", "image/jpeg", jpegData[:bytes.Index(jpegData, []byte{0xFF, 0xDA})]},
+		{"jpeg segment length past end", "image/jpeg", append([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF}, make([]byte, 10)...)},
+		{"jpeg segment length too small", "image/jpeg", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01, 0xFF, 0xDA}},
+		{"empty png", "image/png", nil},
+		{"png cut inside chunk", "image/png", pngData[:len(pngSignature)+20]},
+		{"png chunk length past end", "image/png", append(append([]byte{}, pngSignature...), 0x7F, 0xFF, 0xFF, 0xFF, 'I', 'H', 'D', 'R', 0, 0, 0, 0)},
+		{"empty webp", "image/webp", nil},
+		{"webp cut inside chunk", "image/webp", webpData[:26]},
+		{"webp chunk size past end", "image/webp", append(append([]byte{}, webpData[:12]...), 'E', 'X', 'I', 'F', 0xFF, 0xFF, 0xFF, 0x7F)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := StripMetadata(tt.data, tt.contentType); !errors.Is(err, errMalformedImage) {
+				t.Errorf("error = %v, want errMalformedImage", err)
+			}
+		})
+	}
+}
+
+// Setiap potongan input tidak boleh membuat parser panic
+func TestStripTruncatedDoesNotPanic(t *testing.T) {
+	inputs := map[string][]byte{
+		"image/jpeg": testJPEG(t, 6),
+		"image/png":  testPNG(t),
+		"image/webp": testWebP(),
+	}
+	for contentType, data := range inputs {
+		for i := 0; i <= len(data); i++ {
+			StripMetadata(data[:i], contentType)
+		}
+	}
+
+	exif := testEXIF(6)
+	for i := 0; i <= len(exif); i++ {
+		parseEXIF(exif[:i])
+	}
+	// Offset IFD yang menunjuk ke luar data
+	corrupt := append([]byte{}, exif...)
+	binary.BigEndian.PutUint32(corrupt[4:], 0xFFFFFFF0)
+	if _, err := parseEXIF(corrupt); err == nil {
+		t.Error("parseEXIF accepted an IFD offset past the end")
+	}
+}
@@ -0,0 +1,204 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"os"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/storage"
+	"gorm.io/gorm"
+)
+
+// maxSourcePixels membatasi ukuran gambar yang mau di-decode agar file kecil
+// dengan dimensi sangat besar tidak menghabiskan memori
+const maxSourcePixels = 50_000_000
+
+// Pipeline membuat variant foto di background menggunakan sejumlah worker.
+type Pipeline struct {
+	Specs []VariantSpec
+	queue chan uint
+}
+
+var pipeline *Pipeline
+
+// Start menjalankan pipeline variant dengan konfigurasi dari environment
+// PHOTO_VARIANTS dan PHOTO_VARIANT_WORKERS, lalu mengantrekan foto yang
+// belum memiliki variant (misalnya karena server berhenti di tengah proses).
+func Start() error {
+	specs := DefaultVariants
+	if value := os.Getenv("PHOTO_VARIANTS"); value != "" {
+		parsed, err := ParseVariantSpecs(value)
+		if err != nil {
+			return err
+		}
+		specs = parsed
+	}
+
+	workers := config.EnvInt("PHOTO_VARIANT_WORKERS", 2)
+
+	pipeline = &Pipeline{Specs: specs, queue: make(chan uint, 1000)}
+	for i := 0; i < workers; i++ {
+		go pipeline.work()
+	}
+
+	var pending []uint
+	err := config.DB.Model(&models.Photo{}).
+		Where("storage_key <> ''").
+		Where("NOT EXISTS (SELECT 1 FROM photo_variants WHERE photo_variants.photo_id = photos.id)").
+		Pluck("id", &pending).Error
+	if err != nil {
+		return err
+	}
+	go func() {
+		for _, id := range pending {
+			Enqueue(id)
+		}
+	}()
+
+	return nil
+}
+
+// Enqueue mengantrekan foto untuk dibuatkan variant-nya.
+func Enqueue(photoID uint) {
+	if pipeline == nil {
+		return
+	}
+
+	select {
+	case pipeline.queue <- photoID:
+	default:
+		// Antrean penuh, foto akan diproses ulang saat Start berikutnya
+		log.Printf("variant queue is full, skipping photo %d", photoID)
+	}
+}
+
+func (p *Pipeline) work() {
+	for photoID := range p.queue {
+		if err := p.Process(photoID); err != nil {
+			log.Printf("failed to generate variants for photo %d: %v", photoID, err)
+		}
+	}
+}
+
+// Process membuat semua variant untuk satu foto dan menyimpannya ke storage dan database.
+func (p *Pipeline) Process(photoID uint) error {
+	ctx := context.Background()
+
+	var photo models.Photo
+	if err := config.DB.Where("id = ?", photoID).First(&photo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if photo.StorageKey == "" || len(p.Specs) == 0 {
+		return nil
+	}
+
+	original, err := storage.Default.Get(ctx, photo.StorageKey)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(original)
+	original.Close()
+	if err != nil {
+		return err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if cfg.Width*cfg.Height > maxSourcePixels {
+		return fmt.Errorf("image is too large to process (%dx%d)", cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if photo.ContentType == "image/jpeg" {
+		src = ApplyOrientation(src, ExtractOrientation(data))
+	}
+
+	var variants []models.PhotoVariant
+	for _, spec := range p.Specs {
+		resized := Resize(src, spec)
+
+		var buf bytes.Buffer
+		if err := Encode(&buf, resized, spec.Format); err != nil {
+			removeVariants(ctx, variants)
+			return err
+		}
+
+		contentType, ext := ContentType(spec.Format)
+		// Key acak agar variant foto privat tidak bisa ditebak dan proses ulang
+		// tidak menimpa variant lama yang masih tercatat
+		key, err := storage.NewKey(fmt.Sprintf("variants/%d", photo.ID), ext)
+		if err != nil {
+			removeVariants(ctx, variants)
+			return err
+		}
+		if err := storage.Default.Put(ctx, key, bytes.NewReader(buf.Bytes()), int64(buf.Len()), contentType); err != nil {
+			removeVariants(ctx, variants)
+			return err
+		}
+
+		variants = append(variants, models.PhotoVariant{
+			PhotoID:     photo.ID,
+			Name:        spec.Name,
+			StorageKey:  key,
+			ContentType: contentType,
+			Width:       resized.Bounds().Dx(),
+			Height:      resized.Bounds().Dy(),
+			Size:        int64(buf.Len()),
+		})
+	}
+
+	var previous []models.PhotoVariant
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Foto bisa saja dihapus selama variant dibuat
+		var count int64
+		if err := tx.Model(&models.Photo{}).Where("id = ?", photo.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("photo_id = ?", photo.ID).Find(&previous).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("photo_id = ?", photo.ID).Delete(&models.PhotoVariant{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&variants).Error
+	})
+	if err != nil {
+		removeVariants(ctx, variants)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	removeVariants(ctx, previous)
+	return nil
+}
+
+// removeVariants menghapus file variant dari storage. Kegagalan hanya dicatat
+// karena file yatim tidak bisa diakses tanpa key-nya.
+func removeVariants(ctx context.Context, variants []models.PhotoVariant) {
+	for _, variant := range variants {
+		if err := storage.Default.Delete(ctx, variant.StorageKey); err != nil {
+			log.Printf("failed to delete variant %s: %v", variant.StorageKey, err)
+		}
+	}
+}
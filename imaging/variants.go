@@ -0,0 +1,153 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+
+	// Decoder tambahan untuk image.Decode
+	_ "image/gif"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+// VariantSpec mendeskripsikan satu ukuran turunan foto.
+// Height 0 berarti tinggi mengikuti rasio aspek. Crop memotong bagian tengah
+// agar hasilnya tepat Width x Height.
+type VariantSpec struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+	Format string
+}
+
+// Format variant yang didukung
+const (
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+)
+
+// DefaultVariants dipakai jika PHOTO_VARIANTS tidak diisi.
+var DefaultVariants = []VariantSpec{
+	{Name: "thumbnail", Width: 150, Height: 150, Crop: true, Format: FormatJPEG},
+	{Name: "medium", Width: 640, Format: FormatJPEG},
+	{Name: "large", Width: 1280, Format: FormatJPEG},
+	{Name: "webp", Width: 1280, Format: FormatWebP},
+}
+
+// ParseVariantSpecs membaca konfigurasi variant dengan format
+// "nama:LEBARxTINGGI[:crop][:format],..." misalnya "thumbnail:150x150:crop,medium:640,webp:1280:webp".
+func ParseVariantSpecs(value string) ([]VariantSpec, error) {
+	var specs []VariantSpec
+	for _, item := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		if len(parts) < 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid variant %q", item)
+		}
+
+		spec := VariantSpec{Name: parts[0], Format: FormatJPEG}
+
+		size := strings.SplitN(parts[1], "x", 2)
+		width, err := strconv.Atoi(size[0])
+		if err != nil || width <= 0 {
+			return nil, fmt.Errorf("invalid width in variant %q", item)
+		}
+		spec.Width = width
+		if len(size) == 2 {
+			if spec.Height, err = strconv.Atoi(size[1]); err != nil || spec.Height < 0 {
+				return nil, fmt.Errorf("invalid height in variant %q", item)
+			}
+		}
+
+		for _, option := range parts[2:] {
+			switch option {
+			case "crop":
+				spec.Crop = true
+			case FormatJPEG, FormatWebP:
+				spec.Format = option
+			default:
+				return nil, fmt.Errorf("unknown option %q in variant %q", option, item)
+			}
+		}
+
+		if spec.Crop && spec.Height == 0 {
+			return nil, fmt.Errorf("variant %q needs a height to crop", item)
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// Resize mengecilkan gambar sesuai spec. Gambar tidak pernah diperbesar.
+func Resize(src image.Image, spec VariantSpec) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	if spec.Crop {
+		// Potong bagian tengah dengan rasio aspek target, lalu skalakan
+		targetRatio := float64(spec.Width) / float64(spec.Height)
+		cropW, cropH := srcW, srcH
+		if float64(srcW)/float64(srcH) > targetRatio {
+			cropW = int(float64(srcH) * targetRatio)
+		} else {
+			cropH = int(float64(srcW) / targetRatio)
+		}
+		x0 := bounds.Min.X + (srcW-cropW)/2
+		y0 := bounds.Min.Y + (srcH-cropH)/2
+		cropRect := image.Rect(x0, y0, x0+cropW, y0+cropH)
+
+		dstW, dstH := min(spec.Width, cropW), min(spec.Height, cropH)
+		dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, cropRect, draw.Src, nil)
+		return dst
+	}
+
+	dstW, dstH := srcW, srcH
+	if spec.Width > 0 && dstW > spec.Width {
+		dstH = dstH * spec.Width / dstW
+		dstW = spec.Width
+	}
+	if spec.Height > 0 && dstH > spec.Height {
+		dstW = dstW * spec.Height / dstH
+		dstH = spec.Height
+	}
+	dstW, dstH = max(dstW, 1), max(dstH, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+	return dst
+}
+
+// Encode menulis gambar dalam format variant.
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case FormatWebP:
+		return nativewebp.Encode(w, img, nil)
+	case FormatJPEG:
+		// JPEG tidak punya alpha, jadi area transparan diberi latar putih
+		if opaque, ok := img.(interface{ Opaque() bool }); ok && !opaque.Opaque() {
+			flat := image.NewRGBA(img.Bounds())
+			draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+			draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+			img = flat
+		}
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+	}
+	return fmt.Errorf("unsupported variant format %q", format)
+}
+
+// ContentType mengembalikan content type dan ekstensi file untuk format variant.
+func ContentType(format string) (string, string) {
+	if format == FormatWebP {
+		return "image/webp", ".webp"
+	}
+	return "image/jpeg", ".jpg"
+}
@@ -0,0 +1,251 @@
+// Package jobs menjalankan pekerjaan background yang disimpan di tabel jobs.
+//
+// Job dijalankan minimal satu kali (at-least-once): worker mengklaim job dengan
+// SELECT ... FOR UPDATE SKIP LOCKED dan menyewa job tersebut selama
+// JOB_LOCK_TIMEOUT_SECONDS. Jika instance mati di tengah jalan, sewa habis dan
+// job diambil lagi oleh instance lain, sehingga handler harus idempoten.
+//
+// Job berulang (lihat Recurring) memakai satu baris, dijamin unik lewat
+// recurring_key, yang dijadwalkan ulang setelah setiap percobaan, sehingga
+// hanya satu instance yang menjalankannya pada satu waktu.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Handler menjalankan satu job. Error membuat job dicoba lagi dengan backoff.
+type Handler func(ctx context.Context, job models.Job) error
+
+// Runner mengklaim dan menjalankan job yang sudah waktunya.
+type Runner struct {
+	ID           string
+	Workers      int
+	PollInterval time.Duration
+	LockTimeout  time.Duration
+	// Retention adalah lama job yang selesai atau gagal permanen disimpan
+	// sebelum dihapus
+	Retention time.Duration
+	db        *gorm.DB
+}
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = map[string]Handler{}
+)
+
+// Register mendaftarkan handler untuk tipe job. Dipanggil sebelum Start.
+func Register(jobType string, handler Handler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	handlers[jobType] = handler
+}
+
+// Enqueue menyimpan job baru yang dijalankan pada runAt. Gunakan tx dari
+// transaksi pemanggil agar job hanya tersimpan jika perubahannya berhasil.
+func Enqueue(tx *gorm.DB, jobType, key string, payload interface{}, runAt time.Time) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	job := models.Job{Type: jobType, Key: key, Payload: string(data), RunAt: runAt}
+	return tx.Create(&job).Error
+}
+
+// Cancel menghapus job dengan key tertentu yang belum selesai
+func Cancel(tx *gorm.DB, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return tx.Where("job_key IN ? AND completed_at IS NULL AND failed_at IS NULL", keys).Delete(&models.Job{}).Error
+}
+
+// Recurring memastikan ada job berulang untuk jobType yang dijalankan setiap
+// interval. Dipanggil saat startup; job yang sudah ada hanya diperbarui
+// intervalnya. Barisnya dibuat dengan INSERT ... ON DUPLICATE KEY UPDATE pada
+// recurring_key, sehingga instance yang mulai bersamaan tidak membuat duplikat.
+func Recurring(jobType string, interval time.Duration) error {
+	seconds := int(interval / time.Second)
+
+	key := jobType
+	job := models.Job{Type: jobType, Key: jobType, RecurringKey: &key, Payload: "{}", RunAt: time.Now(), IntervalSeconds: seconds}
+	return config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "recurring_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"interval_seconds"}),
+	}).Create(&job).Error
+}
+
+// Start menjalankan runner dengan konfigurasi dari environment JOB_WORKERS,
+// JOB_POLL_INTERVAL_SECONDS, JOB_LOCK_TIMEOUT_SECONDS, dan JOB_RETENTION_HOURS.
+func Start() error {
+	id, err := runnerID()
+	if err != nil {
+		return err
+	}
+
+	runner := &Runner{
+		ID:           id,
+		Workers:      config.EnvInt("JOB_WORKERS", 2),
+		PollInterval: time.Duration(config.EnvInt("JOB_POLL_INTERVAL_SECONDS", 5)) * time.Second,
+		LockTimeout:  time.Duration(config.EnvInt("JOB_LOCK_TIMEOUT_SECONDS", 300)) * time.Second,
+		Retention:    time.Duration(config.EnvInt("JOB_RETENTION_HOURS", 7*24)) * time.Hour,
+		db:           config.DB,
+	}
+	for i := 0; i < runner.Workers; i++ {
+		go runner.work()
+	}
+	go runner.purge()
+	return nil
+}
+
+// purge menghapus job yang sudah selesai atau gagal permanen setelah melewati
+// Retention agar tabel jobs tidak terus membesar. Job berulang tidak pernah
+// selesai sehingga tidak ikut terhapus.
+func (r *Runner) purge() {
+	for {
+		cutoff := time.Now().Add(-r.Retention)
+		err := r.db.Where("completed_at < ? OR failed_at < ?", cutoff, cutoff).Delete(&models.Job{}).Error
+		if err != nil {
+			log.Printf("jobs: failed to purge finished jobs: %v", err)
+		}
+		time.Sleep(time.Hour)
+	}
+}
+
+func (r *Runner) work() {
+	for {
+		job, err := r.claim()
+		if err != nil {
+			log.Printf("jobs: failed to claim job: %v", err)
+		}
+		if job == nil {
+			time.Sleep(r.PollInterval)
+			continue
+		}
+		r.finish(*job, r.run(*job))
+	}
+}
+
+// claim mengunci satu job yang sudah waktunya dan belum disewa instance lain
+func (r *Runner) claim() (*models.Job, error) {
+	var job models.Job
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("completed_at IS NULL AND failed_at IS NULL AND run_at <= ?", now).
+			Where("locked_until IS NULL OR locked_until < ?", now).
+			Order("run_at").Take(&job).Error
+		if err != nil {
+			return err
+		}
+
+		lockedUntil := now.Add(r.LockTimeout)
+		job.Attempts++
+		job.LockedBy = r.ID
+		job.LockedUntil = &lockedUntil
+		return tx.Model(&job).Updates(map[string]interface{}{
+			"attempts":     job.Attempts,
+			"locked_by":    job.LockedBy,
+			"locked_until": lockedUntil,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *Runner) run(job models.Job) (err error) {
+	handlersMu.RLock()
+	handler, ok := handlers[job.Type]
+	handlersMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no handler registered for job type %q", job.Type)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.LockTimeout)
+	defer cancel()
+	return handler(ctx, job)
+}
+
+// finish mencatat hasil job. Update hanya berlaku jika sewa masih milik
+// runner ini, sehingga hasil dari sewa yang sudah kedaluwarsa diabaikan.
+func (r *Runner) finish(job models.Job, runErr error) {
+	now := time.Now()
+	updates := map[string]interface{}{"locked_by": "", "locked_until": nil}
+
+	switch {
+	case job.IntervalSeconds > 0 && (runErr == nil || job.Attempts >= job.MaxAttempts):
+		// Job berulang tidak pernah selesai atau gagal permanen, hanya dijadwalkan lagi
+		if runErr != nil {
+			log.Printf("jobs: recurring job %d (%s) failed, waiting for next run: %v", job.ID, job.Type, runErr)
+			updates["last_error"] = runErr.Error()
+		} else {
+			updates["last_error"] = ""
+		}
+		updates["attempts"] = 0
+		updates["run_at"] = now.Add(time.Duration(job.IntervalSeconds) * time.Second)
+	case runErr == nil:
+		updates["completed_at"] = now
+		updates["last_error"] = ""
+	case job.Attempts >= job.MaxAttempts:
+		log.Printf("jobs: job %d (%s) failed permanently: %v", job.ID, job.Type, runErr)
+		updates["failed_at"] = now
+		updates["last_error"] = runErr.Error()
+	default:
+		log.Printf("jobs: job %d (%s) failed, retrying: %v", job.ID, job.Type, runErr)
+		updates["run_at"] = now.Add(backoff(job.Attempts))
+		updates["last_error"] = runErr.Error()
+	}
+
+	err := r.db.Model(&models.Job{}).Where("id = ? AND locked_by = ?", job.ID, r.ID).Updates(updates).Error
+	if err != nil {
+		log.Printf("jobs: failed to record result of job %d: %v", job.ID, err)
+	}
+}
+
+// backoff mengembalikan jeda sebelum percobaan berikutnya: 10 detik lalu
+// berlipat dua setiap kali gagal, maksimal satu jam
+func backoff(attempts int) time.Duration {
+	delay := 10 * time.Second
+	for i := 1; i < attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > time.Hour {
+		delay = time.Hour
+	}
+	return delay
+}
+
+func runnerID() (string, error) {
+	host, _ := os.Hostname()
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(buf)), nil
+}
@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogMailer tidak mengirim email sungguhan, melainkan menulis isinya ke file
+// (jika Path diisi) atau ke log aplikasi. Cocok untuk lingkungan test.
+type LogMailer struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	msg, err := buildMessage("mygram@localhost", to, subject, body)
+	if err != nil {
+		return err
+	}
+
+	if m.Path == "" {
+		log.Printf("mail to %s: %s\n%s", to, subject, body)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n"); err != nil {
+		return err
+	}
+	if _, err := f.Write(msg); err != nil {
+		return err
+	}
+	_, err = f.WriteString("\r\n\r\n")
+	return err
+}
@@ -0,0 +1,69 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Mailer adalah abstraksi pengiriman email agar implementasinya bisa diganti
+// (SMTP di produksi, file/log di lingkungan test).
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// Default adalah mailer yang dipakai aplikasi, diisi oleh Init.
+var Default Mailer = &LogMailer{}
+
+// Init memilih implementasi mailer dari environment MAIL_DRIVER (smtp atau log).
+func Init() error {
+	switch driver := os.Getenv("MAIL_DRIVER"); driver {
+	case "smtp":
+		m := &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		}
+		if m.Host == "" || m.From == "" {
+			return errors.New("SMTP_HOST and MAIL_FROM are required for the smtp mail driver")
+		}
+		if m.Port == "" {
+			m.Port = "587"
+		}
+		Default = m
+	case "", "log":
+		Default = &LogMailer{Path: os.Getenv("MAIL_LOG_PATH")}
+	default:
+		return fmt.Errorf("unknown MAIL_DRIVER %q", driver)
+	}
+
+	return nil
+}
+
+// Send mengirim email menggunakan mailer default.
+func Send(to, subject, body string) error {
+	return Default.Send(to, subject, body)
+}
+
+// buildMessage menyusun pesan email sederhana dalam format RFC 5322.
+func buildMessage(from, to, subject, body string) ([]byte, error) {
+	for _, header := range []string{from, to, subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, errors.New("mail headers must not contain line breaks")
+		}
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+
+	return []byte(msg.String()), nil
+}
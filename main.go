@@ -1,8 +1,18 @@
 package main
 
 import (
+	"github.com/Mikael88/go-mygram/auth"
 	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/controllers"
+	"github.com/Mikael88/go-mygram/feed"
+	"github.com/Mikael88/go-mygram/imaging"
+	"github.com/Mikael88/go-mygram/jobs"
+	"github.com/Mikael88/go-mygram/mailer"
 	"github.com/Mikael88/go-mygram/routes"
+	"github.com/Mikael88/go-mygram/search"
+	"github.com/Mikael88/go-mygram/storage"
+	"github.com/Mikael88/go-mygram/throttle"
+	"github.com/Mikael88/go-mygram/trending"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -14,9 +24,43 @@ func main() {
 		panic("Failed to load environment variables")
 	}
 
+	if err := auth.LoadKeys(); err != nil {
+		panic("Failed to load JWT signing keys: " + err.Error())
+	}
+
+	if err := mailer.Init(); err != nil {
+		panic("Failed to configure mailer: " + err.Error())
+	}
+
+	throttle.Init()
+
+	if err := storage.Init(); err != nil {
+		panic("Failed to configure storage: " + err.Error())
+	}
+
 	config.InitDB()
 	config.RunMigration()
 
+	if err := feed.Init(); err != nil {
+		panic("Failed to configure feed: " + err.Error())
+	}
+
+	if err := search.Init(); err != nil {
+		panic("Failed to configure search: " + err.Error())
+	}
+
+	if err := imaging.Start(); err != nil {
+		panic("Failed to start photo variant pipeline: " + err.Error())
+	}
+
+	controllers.RegisterJobs()
+	if err := trending.Start(); err != nil {
+		panic("Failed to schedule trending tags: " + err.Error())
+	}
+	if err := jobs.Start(); err != nil {
+		panic("Failed to start job runner: " + err.Error())
+	}
+
 	r := gin.Default()
 
 	routes.SetupRoutes(r)
@@ -3,11 +3,20 @@ package middlewares
 import (
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/Mikael88/go-mygram/auth"
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/models"
 	"github.com/gin-gonic/gin"
 	"github.com/dgrijalva/jwt-go"
 )
 
+const (
+	authMethodSession = "session"
+	authMethodToken   = "token"
+)
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -17,10 +26,20 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		tokenString := strings.Split(authHeader, "Bearer ")[1]
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte("your_secret_key"), nil
-		})
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		if tokenString == authHeader || tokenString == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization must use the Bearer scheme"})
+			c.Abort()
+			return
+		}
+
+		// Personal access token dikenali dari prefix-nya
+		if strings.HasPrefix(tokenString, auth.PersonalAccessTokenPrefix) {
+			authenticatePersonalAccessToken(c, tokenString)
+			return
+		}
+
+		token, err := auth.Parse(tokenString)
 
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
@@ -35,17 +54,64 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || !token.Valid {
+		if !ok || !token.Valid || claims["typ"] != "access" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 			c.Abort()
 			return
 		}
 
-		userId := claims["userId"].(float64)
+		userId, ok := claims["userId"].(float64)
+		sessionId, hasSession := claims["sid"].(float64)
+		if !ok || !hasSession {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			c.Abort()
+			return
+		}
+
+		// Token hanya berlaku selama sesinya belum dicabut
+		var session models.Session
+		if err := config.DB.Where("id = ? AND user_id = ?", uint(sessionId), uint(userId)).First(&session).Error; err != nil || !session.Active() {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has expired or been revoked"})
+			c.Abort()
+			return
+		}
+
+		role, _ := claims["role"].(string)
 
 		c.Set("userId", uint(userId))
+		c.Set("role", role)
+		c.Set("sessionId", session.ID)
+		c.Set("authMethod", authMethodSession)
 
 		c.Next()
 	}
 }
+
+func authenticatePersonalAccessToken(c *gin.Context, tokenString string) {
+	var pat models.PersonalAccessToken
+	if err := config.DB.Where("token_hash = ?", auth.HashToken(tokenString)).First(&pat).Error; err != nil || !pat.Active() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return
+	}
+
+	var user models.User
+	if err := config.DB.Select("id", "role").Where("id = ?", pat.UserID).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return
+	}
+
+	// Cukup catat pemakaian terakhir sekali per menit
+	if pat.LastUsedAt == nil || time.Since(*pat.LastUsedAt) > time.Minute {
+		config.DB.Model(&pat).Update("last_used_at", time.Now())
+	}
+
+	c.Set("userId", pat.UserID)
+	c.Set("role", user.Role)
+	c.Set("tokenScopes", pat.ScopeList())
+	c.Set("authMethod", authMethodToken)
+
+	c.Next()
+}
 	
\ No newline at end of file
@@ -0,0 +1,124 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Mikael88/go-mygram/auth"
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Owned adalah resource yang dimiliki seorang user.
+type Owned interface {
+	OwnerID() uint
+}
+
+// ResourceLoader memuat resource berdasarkan ID dari path parameter.
+// Load harus mengembalikan gorm.ErrRecordNotFound jika resource tidak ada.
+type ResourceLoader interface {
+	// Key adalah nama key di gin context tempat resource disimpan
+	Key() string
+	// Name dipakai dalam pesan error, misalnya "Photo not found"
+	Name() string
+	Load(id uint) (Owned, error)
+}
+
+// Grant mengizinkan user yang bukan pemilik untuk mengakses resource,
+// misalnya moderator atau kolaborator.
+type Grant func(c *gin.Context, resource Owned) bool
+
+// modelLoader adalah ResourceLoader untuk model GORM yang mengimplementasikan Owned.
+type modelLoader[T any, PT interface {
+	*T
+	Owned
+}] struct {
+	key  string
+	name string
+}
+
+func (l modelLoader[T, PT]) Key() string  { return l.key }
+func (l modelLoader[T, PT]) Name() string { return l.name }
+
+func (l modelLoader[T, PT]) Load(id uint) (Owned, error) {
+	var model T
+	if err := config.DB.Where("id = ?", id).First(&model).Error; err != nil {
+		return nil, err
+	}
+	return PT(&model), nil
+}
+
+// Loader untuk resource yang dilindungi kepemilikan. Handler mengambil resource
+// yang sudah dimuat dengan c.MustGet(loader.Key()).
+var (
+	PhotoLoader       ResourceLoader = modelLoader[models.Photo, *models.Photo]{key: "photo", name: "Photo"}
+	CommentLoader     ResourceLoader = modelLoader[models.Comment, *models.Comment]{key: "comment", name: "Comment"}
+	SocialMediaLoader ResourceLoader = modelLoader[models.SocialMedia, *models.SocialMedia]{key: "socialMedia", name: "Social media"}
+	// Pemilik permintaan akses adalah pemilik akun privat yang dimintai akses
+	AccessRequestLoader ResourceLoader = modelLoader[models.AccessRequest, *models.AccessRequest]{key: "accessRequest", name: "Access request"}
+	NotificationLoader  ResourceLoader = modelLoader[models.Notification, *models.Notification]{key: "notification", name: "Notification"}
+)
+
+// AuthorizeOwner memuat resource dari path parameter param satu kali, menyimpannya
+// di context untuk dipakai handler, lalu hanya mengizinkan pemilik atau user yang
+// lolos salah satu grant. Resource yang tidak ada selalu menghasilkan 404,
+// sedangkan resource milik orang lain menghasilkan 403.
+func AuthorizeOwner(loader ResourceLoader, param string, grants ...Grant) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, exists := c.Get("userId")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		notFound := gin.H{"error": loader.Name() + " not found"}
+
+		id, err := strconv.ParseUint(c.Param(param), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusNotFound, notFound)
+			c.Abort()
+			return
+		}
+
+		resource, err := loader.Load(uint(id))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, notFound)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load " + loader.Name()})
+			c.Abort()
+			return
+		}
+
+		if resource.OwnerID() != userId.(uint) && !anyGrant(c, resource, grants) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to perform this action"})
+			c.Abort()
+			return
+		}
+
+		c.Set(loader.Key(), resource)
+		c.Next()
+	}
+}
+
+// GrantPermission mengizinkan user yang role-nya memiliki permission.
+func GrantPermission(permission string) Grant {
+	return func(c *gin.Context, resource Owned) bool {
+		return auth.HasPermission(c.GetString("role"), permission)
+	}
+}
+
+func anyGrant(c *gin.Context, resource Owned, grants []Grant) bool {
+	for _, grant := range grants {
+		if grant(c, resource) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/Mikael88/go-mygram/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole hanya mengizinkan user dengan salah satu role yang disebutkan.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to perform this action"})
+		c.Abort()
+	}
+}
+
+// RequirePermission hanya mengizinkan user yang role-nya memiliki permission.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.HasPermission(c.GetString("role"), permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to perform this action"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope memastikan personal access token memiliki scope yang dibutuhkan.
+// Request yang diautentikasi dengan sesi login memiliki akses penuh.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if HasScope(c, scope) {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Token is missing the required scope: " + scope})
+		c.Abort()
+	}
+}
+
+// HasScope mengembalikan true jika request boleh memakai scope, yaitu
+// diautentikasi dengan sesi login atau dengan token yang memiliki scope tersebut.
+// Dipakai handler yang hasilnya bergantung pada scope, misalnya pencarian.
+func HasScope(c *gin.Context, scope string) bool {
+	if c.GetString("authMethod") != authMethodToken {
+		return true
+	}
+
+	for _, s := range c.GetStringSlice("tokenScopes") {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireSession hanya mengizinkan request yang diautentikasi dengan sesi login,
+// misalnya untuk pengelolaan akun dan token.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("authMethod") != authMethodSession {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This action requires a login session"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
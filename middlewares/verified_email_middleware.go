@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerifiedEmail menolak request dari user yang emailnya belum diverifikasi
+// ketika kebijakan REQUIRE_VERIFIED_EMAIL=true diaktifkan.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if os.Getenv("REQUIRE_VERIFIED_EMAIL") != "true" {
+			c.Next()
+			return
+		}
+
+		userId, exists := c.Get("userId")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		var user models.User
+		if err := config.DB.Select("id", "email_verified_at").Where("id = ?", userId).First(&user).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		if !user.IsEmailVerified() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address first"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
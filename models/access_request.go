@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Status permintaan akses ke akun privat
+const (
+	AccessPending  = "pending"
+	AccessApproved = "approved"
+	AccessRejected = "rejected"
+)
+
+// AccessRequest adalah permintaan Requester untuk melihat konten akun privat
+// Account. Permintaan yang disetujui memberi akses sampai dicabut.
+type AccessRequest struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	RequesterID uint       `gorm:"uniqueIndex:idx_access_request_pair;not null" json:"requester_id"`
+	Requester   User       `gorm:"foreignKey:RequesterID" json:"requester"`
+	AccountID   uint       `gorm:"uniqueIndex:idx_access_request_pair;index;not null" json:"account_id"`
+	Status      string     `gorm:"type:varchar(20);not null" json:"status"`
+	RespondedAt *time.Time `json:"responded_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// OwnerID dipakai AuthorizeOwner: hanya pemilik akun privat yang bisa
+// menanggapi permintaan akses
+func (r *AccessRequest) OwnerID() uint {
+	return r.AccountID
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Block mencatat bahwa Blocker memblokir Blocked. Kedua user tidak bisa
+// melihat konten satu sama lain dan tidak bisa saling mengikuti.
+type Block struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	BlockerID uint      `gorm:"uniqueIndex:idx_block_pair;not null" json:"blocker_id"`
+	Blocker   User      `gorm:"foreignKey:BlockerID" json:"blocker"`
+	BlockedID uint      `gorm:"uniqueIndex:idx_block_pair;index;not null" json:"blocked_id"`
+	Blocked   User      `gorm:"foreignKey:BlockedID" json:"blocked"`
+	CreatedAt time.Time `json:"created_at"`
+}
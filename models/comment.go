@@ -8,7 +8,17 @@ type Comment struct {
     User      User         `json:"user"`
     PhotoID   uint         `json:"photo_id"`
     Photo     Photo        `json:"photo"`
-    Message   string       `gorm:"not null" json:"message" validate:"required"`
+    Message   string       `gorm:"type:longtext;not null;index:idx_comment_search,class:FULLTEXT" json:"message" validate:"required"`
+    // ParentID terisi jika komentar ini adalah balasan untuk komentar lain
+    ParentID   *uint       `gorm:"index" json:"parent_id"`
+    Depth      int         `gorm:"not null;default:0" json:"depth"`
+    ReplyCount int         `gorm:"not null;default:0" json:"reply_count"`
+    LikeCount  int         `gorm:"not null;default:0" json:"like_count"`
     CreatedAt time.Time    `json:"created_at"`
     UpdatedAt time.Time    `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+// OwnerID mengembalikan ID user pemilik komentar
+func (c *Comment) OwnerID() uint {
+	return c.UserID
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// DataMigration mencatat langkah migrasi data yang cukup dijalankan sekali,
+// misalnya backfill hashtag untuk konten lama.
+type DataMigration struct {
+	Name  string    `gorm:"primaryKey;type:varchar(100)" json:"name"`
+	RanAt time.Time `gorm:"not null" json:"ran_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Follow mencatat bahwa Follower mengikuti Followee
+type Follow struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	FollowerID uint      `gorm:"uniqueIndex:idx_follow_pair;not null" json:"follower_id"`
+	Follower   User      `gorm:"foreignKey:FollowerID" json:"follower"`
+	FolloweeID uint      `gorm:"uniqueIndex:idx_follow_pair;index;not null" json:"followee_id"`
+	Followee   User      `gorm:"foreignKey:FolloweeID" json:"followee"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job adalah pekerjaan background yang disimpan di database agar tetap
+// dijalankan walaupun server restart. Job diklaim dengan mengunci barisnya
+// sehingga aman dijalankan oleh beberapa instance sekaligus.
+type Job struct {
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Type string `gorm:"type:varchar(50);not null" json:"type"`
+	// Key mengidentifikasi job untuk dibatalkan atau dijadwalkan ulang,
+	// misalnya "publish_photo:12"
+	Key         string     `gorm:"column:job_key;type:varchar(100);index" json:"key"`
+	Payload     string     `gorm:"type:text" json:"payload"`
+	RunAt       time.Time  `gorm:"index;not null" json:"run_at"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int        `gorm:"not null;default:5" json:"max_attempts"`
+	LockedBy    string     `gorm:"type:varchar(100)" json:"-"`
+	LockedUntil *time.Time `json:"-"`
+	LastError   string     `gorm:"type:text" json:"last_error"`
+	CompletedAt *time.Time `gorm:"index" json:"completed_at"`
+	FailedAt    *time.Time `gorm:"index" json:"failed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// IntervalSeconds lebih dari 0 menandakan job berulang yang tidak pernah
+	// selesai, melainkan dijadwalkan lagi setelah setiap percobaan
+	IntervalSeconds int `gorm:"not null;default:0" json:"interval_seconds"`
+	// RecurringKey hanya diisi untuk job berulang. Indeks unik menjamin satu
+	// baris per job berulang walaupun beberapa instance mulai bersamaan.
+	RecurringKey *string `gorm:"type:varchar(100);uniqueIndex" json:"-"`
+}
+
+// Decode membaca payload JSON job ke v
+func (j *Job) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(j.Payload), v)
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Jenis target yang bisa disukai
+const (
+	LikeTargetPhoto   = "photo"
+	LikeTargetComment = "comment"
+)
+
+// Like mencatat bahwa seorang user menyukai foto atau komentar. Setiap user
+// hanya bisa menyukai sebuah target satu kali.
+type Like struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TargetType string    `gorm:"type:varchar(20);uniqueIndex:idx_like_target_user;not null" json:"target_type"`
+	TargetID   uint      `gorm:"uniqueIndex:idx_like_target_user;not null" json:"target_id"`
+	UserID     uint      `gorm:"uniqueIndex:idx_like_target_user;index;not null" json:"user_id"`
+	User       User      `json:"user"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Mute mencatat bahwa Muter tidak ingin melihat konten Muted di feed dan
+// daftar komentar. Muted tidak diberi tahu dan tetap bisa melihat konten Muter.
+type Mute struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	MuterID   uint      `gorm:"uniqueIndex:idx_mute_pair;not null" json:"muter_id"`
+	Muter     User      `gorm:"foreignKey:MuterID" json:"muter"`
+	MutedID   uint      `gorm:"uniqueIndex:idx_mute_pair;index;not null" json:"muted_id"`
+	Muted     User      `gorm:"foreignKey:MutedID" json:"muted"`
+	CreatedAt time.Time `json:"created_at"`
+}
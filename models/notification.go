@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Jenis notifikasi
+const (
+	// NotificationCommentCreated dikirim ke pemilik foto saat fotonya dikomentari
+	NotificationCommentCreated = "comment_created"
+	// NotificationCommentEdited dikirim ke pemilik foto saat komentar di fotonya diubah
+	NotificationCommentEdited = "comment_edited"
+	// NotificationPhotoDeleted dikirim ke user yang pernah mengomentari foto yang dihapus
+	NotificationPhotoDeleted = "photo_deleted"
+	// NotificationSecurity dikirim saat ada kejadian penting di akun, misalnya
+	// login baru atau password diganti. Tidak bisa dimatikan.
+	NotificationSecurity = "security"
+)
+
+// Jenis target notifikasi
+const (
+	NotificationTargetPhoto = "photo"
+	NotificationTargetUser  = "user"
+)
+
+// ConfigurableNotifications adalah jenis notifikasi yang bisa dimatikan user
+var ConfigurableNotifications = []string{
+	NotificationCommentCreated,
+	NotificationCommentEdited,
+	NotificationPhotoDeleted,
+}
+
+// Notification adalah satu item di kotak masuk notifikasi user. Kejadian
+// sejenis pada target yang sama digabung ke satu notifikasi selama belum
+// dibaca, misalnya "Ana dan 4 lainnya mengomentari foto kamu".
+type Notification struct {
+	ID     uint   `gorm:"primaryKey" json:"id"`
+	UserID uint   `gorm:"index:idx_notification_inbox;uniqueIndex:idx_notification_unread_group;not null" json:"user_id"`
+	Type   string `gorm:"type:varchar(50);not null" json:"type"`
+	// GroupKey menentukan notifikasi yang digabung. Kosong untuk notifikasi
+	// yang tidak pernah digabung.
+	GroupKey   string `gorm:"type:varchar(100);index" json:"-"`
+	ActorID    *uint  `json:"actor_id"`
+	Actor      *User  `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+	ActorCount int    `gorm:"not null;default:0" json:"actor_count"`
+	TargetType string `gorm:"type:varchar(20)" json:"target_type"`
+	TargetID   uint   `json:"target_id"`
+	// Data berisi detail tambahan dalam JSON, misalnya judul foto yang dihapus
+	Data      string     `gorm:"type:text" json:"-"`
+	ReadAt    *time.Time `gorm:"index:idx_notification_inbox" json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// UnreadGroupKey sama dengan GroupKey selama notifikasi belum dibaca dan
+	// NULL setelahnya. Indeks unik menjamin hanya ada satu notifikasi belum
+	// dibaca per grup walaupun event pertamanya datang bersamaan.
+	UnreadGroupKey *string `gorm:"type:varchar(100);uniqueIndex:idx_notification_unread_group" json:"-"`
+}
+
+// NotificationActor mencatat user berbeda yang memicu notifikasi gabungan
+type NotificationActor struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	NotificationID uint      `gorm:"uniqueIndex:idx_notification_actor;not null" json:"notification_id"`
+	UserID         uint      `gorm:"uniqueIndex:idx_notification_actor;index;not null" json:"user_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// OwnerID dipakai AuthorizeOwner: notifikasi hanya bisa diubah penerimanya
+func (n *Notification) OwnerID() uint {
+	return n.UserID
+}
+
+// DecodeData membaca Data ke v
+func (n *Notification) DecodeData(v interface{}) error {
+	if n.Data == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(n.Data), v)
+}
@@ -2,23 +2,98 @@ package models
 
 import "time"
 
+// Siapa saja yang bisa melihat foto. Foto unlisted bisa dibuka langsung
+// tetapi tidak muncul di daftar, feed, atau pencarian.
+const (
+	PhotoPublic   = "public"
+	PhotoUnlisted = "unlisted"
+	PhotoOnlyMe   = "only_me"
+)
+
+// Status siklus hidup foto. Hanya foto published yang terlihat oleh user lain.
+// Foto scheduled terbit otomatis pada PublishAt.
+const (
+	PhotoDraft     = "draft"
+	PhotoScheduled = "scheduled"
+	PhotoPublished = "published"
+	PhotoArchived  = "archived"
+)
+
 type Photo struct {
 	ID        uint         `gorm:"primaryKey" json:"id"`
-    Title     string       `gorm:"not null" json:"title" validate:"required"`
-    Caption   string       `json:"caption"`
+    Title     string       `gorm:"type:longtext;not null;index:idx_photo_search,class:FULLTEXT" json:"title" validate:"required"`
+    Caption   string       `gorm:"type:longtext;index:idx_photo_search,class:FULLTEXT" json:"caption"`
     PhotoURL  string       `gorm:"not null" json:"photo_url" validate:"required"`
+    StorageKey  string     `json:"-"`
+    ContentType string     `json:"content_type"`
+    CameraMake  string     `json:"camera_make"`
+    CameraModel string     `json:"camera_model"`
+    TakenAt     *time.Time `json:"taken_at"`
+    Latitude    *float64   `json:"-"`
+    Longitude   *float64   `json:"-"`
+    LocationPublic bool    `json:"location_public"`
+    LikeCount   int        `gorm:"not null;default:0" json:"like_count"`
+    Visibility  string     `gorm:"type:varchar(20);not null;default:public" json:"visibility"`
+    Status      string     `gorm:"type:varchar(20);not null;default:published;index" json:"status"`
+    PublishedAt *time.Time `gorm:"index" json:"published_at"`
+    PublishAt   *time.Time `json:"publish_at"`
     UserID    uint         `json:"user_id"`
     User      User         `json:"user"`
     CreatedAt time.Time    `json:"created_at"`
     UpdatedAt time.Time    `json:"updated_at"`
     Comments  []Comment    `json:"comments"`
+    Variants  []PhotoVariant `json:"variants"`
 }
 
 type PhotoResponse struct {
     ID        uint      `json:"id"`
     Title     string    `json:"title"`
     Caption   string    `json:"caption"`
+    Tags      []TagEntity `json:"tags"`
     PhotoURL  string    `json:"photo_url"`
     UserID    uint      `json:"user_id"`
+    Variants  map[string]string `json:"variants"`
+    CameraMake  string     `json:"camera_make,omitempty"`
+    CameraModel string     `json:"camera_model,omitempty"`
+    TakenAt     *time.Time `json:"taken_at,omitempty"`
+    Location    *PhotoLocation `json:"location,omitempty"`
+    LikeCount   int        `json:"like_count"`
+    LikedByMe   bool       `json:"liked_by_me"`
+    Visibility  string     `json:"visibility"`
+    Status      string     `json:"status"`
+    PublishedAt *time.Time `json:"published_at"`
+    PublishAt   *time.Time `json:"publish_at,omitempty"`
     CreatedAt time.Time `json:"created_at"`
-  }
\ No newline at end of file
+  }
+
+// PhotoLocation adalah lokasi kasar foto yang dipublikasikan pemiliknya
+type PhotoLocation struct {
+    Latitude  float64 `json:"latitude"`
+    Longitude float64 `json:"longitude"`
+}
+
+// OwnerID mengembalikan ID user pemilik foto
+func (p *Photo) OwnerID() uint {
+	return p.UserID
+}
+
+// ValidPhotoVisibility mengembalikan true jika visibility dikenal
+func ValidPhotoVisibility(visibility string) bool {
+	return visibility == PhotoPublic || visibility == PhotoUnlisted || visibility == PhotoOnlyMe
+}
+
+// PublishedTime mengembalikan waktu publikasi, atau waktu nol untuk draft
+func (p *Photo) PublishedTime() time.Time {
+	if p.PublishedAt == nil {
+		return time.Time{}
+	}
+	return *p.PublishedAt
+}
+
+// PublicLocation mengembalikan lokasi kasar foto jika pemilik memilih untuk mempublikasikannya
+func (p *Photo) PublicLocation() *PhotoLocation {
+	if !p.LocationPublic || p.Latitude == nil || p.Longitude == nil {
+		return nil
+	}
+	return &PhotoLocation{Latitude: *p.Latitude, Longitude: *p.Longitude}
+}
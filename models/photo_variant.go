@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PhotoVariant adalah versi foto yang sudah diubah ukurannya, misalnya thumbnail.
+type PhotoVariant struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	PhotoID     uint      `gorm:"uniqueIndex:idx_photo_variant_name;not null" json:"photo_id"`
+	Name        string    `gorm:"type:varchar(50);uniqueIndex:idx_photo_variant_name;not null" json:"name"`
+	StorageKey  string    `gorm:"not null" json:"-"`
+	ContentType string    `json:"content_type"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import "gorm.io/gorm"
+
+// Jenis dokumen di index pencarian
+const (
+	SearchKindUser    = "user"
+	SearchKindPhoto   = "photo"
+	SearchKindComment = "comment"
+)
+
+// SearchIndexer menerima perubahan user, foto, dan komentar dari hook model
+// agar index pencarian tetap sinkron
+type SearchIndexer interface {
+	Index(tx *gorm.DB, kind string, id uint, text string) error
+	Remove(tx *gorm.DB, kind string, id uint) error
+}
+
+// Search adalah index yang diberi tahu oleh hook model. Diisi oleh package
+// search saat startup; jika nil, hook tidak melakukan apa pun.
+var Search SearchIndexer
+
+// SearchText mengembalikan teks user yang bisa dicari
+func (u *User) SearchText() string {
+	return u.Username
+}
+
+// SearchText mengembalikan teks foto yang bisa dicari
+func (p *Photo) SearchText() string {
+	return p.Title + "\n" + p.Caption
+}
+
+// SearchText mengembalikan teks komentar yang bisa dicari
+func (c *Comment) SearchText() string {
+	return c.Message
+}
+
+func (u *User) AfterSave(tx *gorm.DB) error {
+	return indexDocument(tx, SearchKindUser, u.ID, u.SearchText())
+}
+
+func (u *User) AfterDelete(tx *gorm.DB) error {
+	return removeDocument(tx, SearchKindUser, u.ID)
+}
+
+func (p *Photo) AfterSave(tx *gorm.DB) error {
+	return indexDocument(tx, SearchKindPhoto, p.ID, p.SearchText())
+}
+
+func (p *Photo) AfterDelete(tx *gorm.DB) error {
+	return removeDocument(tx, SearchKindPhoto, p.ID)
+}
+
+func (c *Comment) AfterSave(tx *gorm.DB) error {
+	return indexDocument(tx, SearchKindComment, c.ID, c.SearchText())
+}
+
+func (c *Comment) AfterDelete(tx *gorm.DB) error {
+	return removeDocument(tx, SearchKindComment, c.ID)
+}
+
+// RemoveSearchDocuments menghapus dokumen dari index setelah penghapusan
+// massal seperti tx.Where("photo_id = ?", id).Delete(&Comment{}). Hook dari
+// penghapusan seperti itu tidak membawa ID sehingga dilewati, dan tanpa
+// pemanggilan ini dokumennya tertinggal di index memory.
+func RemoveSearchDocuments(tx *gorm.DB, kind string, ids []uint) error {
+	for _, id := range ids {
+		if err := removeDocument(tx, kind, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexDocument meneruskan perubahan ke index. Hook dari query massal, misalnya
+// Model(&Photo{}).Where(...).Update(...) atau Where(...).Delete(&Comment{}),
+// tidak membawa ID dan dilewati; penghapusan massal harus memanggil
+// RemoveSearchDocuments.
+func indexDocument(tx *gorm.DB, kind string, id uint, text string) error {
+	if Search == nil || id == 0 {
+		return nil
+	}
+	return Search.Index(tx, kind, id, text)
+}
+
+func removeDocument(tx *gorm.DB, kind string, id uint) error {
+	if Search == nil || id == 0 {
+		return nil
+	}
+	return Search.Remove(tx, kind, id)
+}
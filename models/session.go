@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Session merepresentasikan sesi login yang dapat dicabut di sisi server.
+type Session struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	UserID           uint       `gorm:"index;not null" json:"user_id"`
+	RefreshTokenHash string     `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	UserAgent        string     `json:"user_agent"`
+	IP               string     `json:"ip"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// Active mengembalikan true jika sesi belum dicabut dan belum kedaluwarsa.
+func (s *Session) Active() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
@@ -10,4 +10,9 @@ type SocialMedia struct {
     User           User         `json:"user"`
     CreatedAt      time.Time    `json:"created_at"`
     UpdatedAt      time.Time    `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+// OwnerID mengembalikan ID user pemilik data media sosial
+func (s *SocialMedia) OwnerID() uint {
+	return s.UserID
+}
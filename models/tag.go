@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Jenis konten yang bisa memuat hashtag
+const (
+	TagTargetPhoto   = "photo"
+	TagTargetComment = "comment"
+)
+
+// Tag adalah hashtag dalam bentuk ternormalisasi. Collation biner dipakai agar
+// tag yang hanya berbeda aksen, misalnya cafe dan café, tetap terpisah.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"type:varchar(400) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin;uniqueIndex;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagLink menghubungkan tag dengan foto atau komentar yang memuatnya. UserID
+// adalah pemilik konten.
+type TagLink struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TagID      uint      `gorm:"uniqueIndex:idx_tag_link;not null" json:"tag_id"`
+	Tag        Tag       `json:"tag"`
+	TargetType string    `gorm:"type:varchar(20);uniqueIndex:idx_tag_link;index:idx_tag_link_target;not null" json:"target_type"`
+	TargetID   uint      `gorm:"uniqueIndex:idx_tag_link;index:idx_tag_link_target;not null" json:"target_id"`
+	UserID     uint      `gorm:"index;not null" json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TagEntity adalah satu hashtag di dalam caption atau komentar. Start dan End
+// adalah offset rune (code point) dari tanda # sampai setelah karakter
+// terakhir tag, sedangkan Text adalah tag seperti yang ditulis user.
+type TagEntity struct {
+	Tag   string `json:"tag"`
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
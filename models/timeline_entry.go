@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// TimelineEntry adalah satu foto di timeline materialisasi seorang user,
+// dipakai oleh strategi feed fan-out-on-write. CreatedAt berisi waktu publikasi
+// foto agar feed bisa diurutkan langsung dari index tabel ini.
+type TimelineEntry struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_timeline_user_photo;index:idx_timeline_user_created,priority:1;not null" json:"user_id"`
+	PhotoID   uint      `gorm:"uniqueIndex:idx_timeline_user_photo;index;not null" json:"photo_id"`
+	CreatedAt time.Time `gorm:"index:idx_timeline_user_created,priority:2" json:"created_at"`
+}
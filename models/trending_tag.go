@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// TrendingTag adalah hasil perhitungan trending sebuah tag untuk satu window
+// waktu. Tabel ini dihitung ulang secara berkala oleh worker trending.
+type TrendingTag struct {
+	ID     uint   `gorm:"primaryKey" json:"id"`
+	Window string `gorm:"column:time_window;type:varchar(10);uniqueIndex:idx_trending_window_tag;index:idx_trending_window_score;not null" json:"window"`
+	TagID  uint   `gorm:"uniqueIndex:idx_trending_window_tag;not null" json:"tag_id"`
+	Tag    Tag    `json:"tag"`
+	// Score adalah jumlah skor per user yang meluruh seiring umur pemakaian tag
+	Score         float64   `gorm:"index:idx_trending_window_score;not null" json:"score"`
+	Uses          int64     `gorm:"not null" json:"uses"`
+	DistinctUsers int64     `gorm:"not null" json:"distinct_users"`
+	ComputedAt    time.Time `json:"computed_at"`
+}
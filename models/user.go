@@ -9,10 +9,20 @@ import (
 
 type User struct {
 	ID 			uint 		`gorm:"primaryKey" json:"id"`
-	Username 	string 		`gorm:"unique;not null" json:"username" validate:"required"`
+	Username 	string 		`gorm:"unique;not null;index:idx_user_search,class:FULLTEXT" json:"username" validate:"required"`
 	Email 		string 		`gorm:"unique;not null" json:"email" validate:"required,email"`
 	Password 	string 		`gorm:"not null" json:"password" validate:"required,min=6"`
 	Age 		int 		`gorm:"not null" json:"age" validate:"required,min=8"`
+	Role 		string 		`gorm:"type:varchar(20);not null;default:user" json:"role"`
+	// IsPrivate membatasi foto dan komentar user ke viewer yang sudah disetujui
+	IsPrivate 	bool 		`gorm:"not null;default:false" json:"is_private"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	TOTPSecret 	string 		`json:"-"`
+	TOTPEnabledAt *time.Time `json:"-"`
+	TOTPLastStep int64 		`json:"-"`
+	// NotificationPreferences menyimpan jenis notifikasi yang dimatikan user.
+	// Jenis yang tidak ada di map dianggap aktif.
+	NotificationPreferences map[string]bool `gorm:"type:text;serializer:json" json:"-"`
 	CreatedAt 	time.Time 	`json:"created_at"`
 	UpdateAt 	time.Time 	`json:"updated_at"`
 	Photos		[]Photo 	`json:"photos"`
@@ -21,10 +31,31 @@ type User struct {
 }
 
 type UserResponse struct {
-    Age      int    `json:"age"`
-    Email    string `json:"email"`
+    Age      int    `json:"age,omitempty"`
+    Email    string `json:"email,omitempty"`
     ID       uint   `json:"id"`
     Username string `json:"username"`
+    Role     string `json:"role"`
+    EmailVerified bool `json:"email_verified"`
+    IsPrivate bool `json:"is_private"`
+}
+
+// ProfileResponse adalah profil publik user. Email dan umur hanya diisi
+// untuk pemilik profil sendiri.
+type ProfileResponse struct {
+    UserResponse
+    PhotoCount     int64 `json:"photo_count"`
+    FollowerCount  int64 `json:"follower_count"`
+    FollowingCount int64 `json:"following_count"`
+    FollowedByMe   bool  `json:"followed_by_me"`
+    FollowsMe      bool  `json:"follows_me"`
+    Mutual         bool  `json:"mutual"`
+    // CanView menandakan viewer boleh melihat foto dan komentar user ini
+    CanView        bool   `json:"can_view"`
+    // AccessStatus adalah status permintaan akses viewer ke akun privat
+    AccessStatus   string `json:"access_status,omitempty"`
+    BlockedByMe    bool   `json:"blocked_by_me"`
+    MutedByMe      bool   `json:"muted_by_me"`
 }
 
 type UpdateUserRequest struct {
@@ -37,14 +68,44 @@ type UpdateUserResponse struct {
     Email     string    `json:"email"`
     Username  string    `json:"username"`
     Age       int       `json:"age"`
+    EmailVerified bool  `json:"email_verified"`
     UpdatedAt time.Time `json:"updated_at"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	hashedPassword, err := HashPassword(u.Password)
 	if err != nil {
 		return err
 	}
-	u.Password = string(hashedPassword)
+	u.Password = hashedPassword
 	return nil
-}
\ No newline at end of file
+}
+
+// HashPassword menghasilkan hash bcrypt dari password
+func HashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPassword), nil
+}
+
+// WantsNotification mengembalikan true jika user ingin menerima notifikasi
+// jenis tersebut. Notifikasi keamanan selalu dikirim.
+func (u *User) WantsNotification(notificationType string) bool {
+	if notificationType == NotificationSecurity {
+		return true
+	}
+	enabled, ok := u.NotificationPreferences[notificationType]
+	return !ok || enabled
+}
+
+// IsEmailVerified mengembalikan true jika email user saat ini sudah diverifikasi
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsTwoFactorEnabled mengembalikan true jika user sudah mengaktifkan TOTP
+func (u *User) IsTwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
@@ -0,0 +1,369 @@
+// Package notifications mencatat kejadian ke kotak masuk notifikasi user.
+//
+// Notify dipanggil di dalam transaksi perubahan yang memicunya, sehingga
+// notifikasi hanya tercatat jika perubahannya berhasil. Komentar baru pada foto
+// yang sama digabung ke satu notifikasi selama notifikasi tersebut belum
+// dibaca, begitu juga komentar yang diubah. Keduanya tidak digabung satu sama
+// lain karena pesannya berbeda.
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Mikael88/go-mygram/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Kejadian keamanan akun yang dikirim sebagai notifikasi security
+const (
+	SecurityNewLogin          = "new_login"
+	SecurityPasswordChanged   = "password_changed"
+	SecurityPasswordReset     = "password_reset"
+	SecurityEmailChanged      = "email_changed"
+	SecurityTwoFactorEnabled  = "two_factor_enabled"
+	SecurityTwoFactorDisabled = "two_factor_disabled"
+	SecurityTokenCreated      = "token_created"
+)
+
+var securityMessages = map[string]string{
+	SecurityNewLogin:          "New login to your account",
+	SecurityPasswordChanged:   "Your password was changed",
+	SecurityPasswordReset:     "Your password was reset",
+	SecurityEmailChanged:      "Your email address was changed",
+	SecurityTwoFactorEnabled:  "Two-factor authentication was enabled",
+	SecurityTwoFactorDisabled: "Two-factor authentication was disabled",
+	SecurityTokenCreated:      "A new personal access token was created",
+}
+
+// aggregated adalah jenis notifikasi yang digabung per target
+var aggregated = map[string]bool{
+	models.NotificationCommentCreated: true,
+	models.NotificationCommentEdited:  true,
+}
+
+func aggregatedTypes() []string {
+	types := make([]string, 0, len(aggregated))
+	for notificationType := range aggregated {
+		types = append(types, notificationType)
+	}
+	return types
+}
+
+// Event adalah satu kejadian untuk satu penerima. ActorID 0 berarti kejadian
+// tidak dipicu user lain, misalnya kejadian keamanan.
+type Event struct {
+	UserID     uint
+	Type       string
+	ActorID    uint
+	TargetType string
+	TargetID   uint
+	Data       map[string]interface{}
+}
+
+// Notify mencatat event ke kotak masuk penerima. Event dilewati jika penerima
+// adalah pelakunya sendiri, sudah dihapus, mematikan jenis notifikasi
+// tersebut, atau memblokir, diblokir, atau me-mute pelakunya.
+func Notify(tx *gorm.DB, event Event) error {
+	if event.ActorID != 0 && event.ActorID == event.UserID {
+		return nil
+	}
+
+	var recipient models.User
+	err := tx.Select("id", "notification_preferences").Where("id = ?", event.UserID).Take(&recipient).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !recipient.WantsNotification(event.Type) {
+		return nil
+	}
+
+	if event.ActorID != 0 {
+		hidden, err := actorHidden(tx, event.UserID, event.ActorID)
+		if err != nil || hidden {
+			return err
+		}
+	}
+
+	data := ""
+	if event.Data != nil {
+		encoded, err := json.Marshal(event.Data)
+		if err != nil {
+			return err
+		}
+		data = string(encoded)
+	}
+
+	groupKey := ""
+	if aggregated[event.Type] {
+		groupKey = fmt.Sprintf("%s:%s:%d", event.Type, event.TargetType, event.TargetID)
+
+		existing, err := unreadGroup(tx, event.UserID, groupKey)
+		if err != nil {
+			return err
+		}
+		if existing.ID != 0 {
+			return aggregate(tx, existing, event.ActorID, data)
+		}
+	}
+
+	notification := models.Notification{
+		UserID:     event.UserID,
+		Type:       event.Type,
+		GroupKey:   groupKey,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Data:       data,
+	}
+	if groupKey != "" {
+		notification.UnreadGroupKey = &groupKey
+	}
+	if event.ActorID != 0 {
+		notification.ActorID = &event.ActorID
+		notification.ActorCount = 1
+	}
+	// Belum ada baris yang bisa dikunci untuk event pertama sebuah grup. Jika
+	// transaksi lain lebih dulu membuatnya, indeks unik menolak insert ini dan
+	// event digabung ke notifikasi tersebut.
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&notification)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		existing, err := unreadGroup(tx, event.UserID, groupKey)
+		if err != nil || existing.ID == 0 {
+			return err
+		}
+		return aggregate(tx, existing, event.ActorID, data)
+	}
+	if event.ActorID == 0 {
+		return nil
+	}
+	return tx.Create(&models.NotificationActor{NotificationID: notification.ID, UserID: event.ActorID}).Error
+}
+
+// Security mencatat kejadian keamanan akun beserta IP dan user agent pemicunya
+func Security(tx *gorm.DB, userID uint, event, ip, userAgent string) error {
+	return Notify(tx, Event{
+		UserID:     userID,
+		Type:       models.NotificationSecurity,
+		TargetType: models.NotificationTargetUser,
+		TargetID:   userID,
+		Data:       map[string]interface{}{"event": event, "ip": ip, "user_agent": userAgent},
+	})
+}
+
+// DeleteTarget menghapus notifikasi yang menunjuk ke target yang sudah dihapus
+func DeleteTarget(tx *gorm.DB, targetType string, targetID uint) error {
+	return deleteWhere(tx, tx.Model(&models.Notification{}).Where("target_type = ? AND target_id = ?", targetType, targetID))
+}
+
+// DeleteUser menghapus kotak masuk user beserta jejaknya sebagai pelaku di
+// notifikasi milik user lain. actor_count dan actor_id notifikasi tersebut
+// dihitung ulang dari pelaku yang tersisa.
+func DeleteUser(tx *gorm.DB, userID uint) error {
+	if err := deleteWhere(tx, tx.Model(&models.Notification{}).Where("user_id = ?", userID)); err != nil {
+		return err
+	}
+
+	var affected []uint
+	err := tx.Model(&models.NotificationActor{}).Where("user_id = ?", userID).Pluck("notification_id", &affected).Error
+	if err != nil || len(affected) == 0 {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.NotificationActor{}).Error; err != nil {
+		return err
+	}
+	return recountActors(tx, affected)
+}
+
+// RemoveActor menghapus user dari pelaku notifikasi gabungan pada target,
+// misalnya setelah semua komentarnya di foto tersebut dihapus, lalu menghitung
+// ulang pelaku yang tersisa
+func RemoveActor(tx *gorm.DB, targetType string, targetID, actorID uint) error {
+	var affected []uint
+	err := tx.Model(&models.Notification{}).
+		Where("target_type = ? AND target_id = ? AND type IN ?", targetType, targetID, aggregatedTypes()).
+		Where("id IN (?)", tx.Model(&models.NotificationActor{}).Select("notification_id").Where("user_id = ?", actorID)).
+		Pluck("id", &affected).Error
+	if err != nil || len(affected) == 0 {
+		return err
+	}
+	if err := tx.Where("notification_id IN ? AND user_id = ?", affected, actorID).Delete(&models.NotificationActor{}).Error; err != nil {
+		return err
+	}
+	return recountActors(tx, affected)
+}
+
+// recountActors menyamakan actor_count dan actor_id dengan notification_actors.
+// Pelaku terbaru yang tersisa menjadi actor_id. Notifikasi gabungan tanpa
+// pelaku dihapus, sedangkan notifikasi lain hanya kehilangan pelakunya karena
+// pesannya tetap berarti, misalnya foto yang dihapus.
+func recountActors(tx *gorm.DB, ids []uint) error {
+	var stats []struct {
+		NotificationID uint
+		Actors         int
+		LastID         uint
+	}
+	err := tx.Model(&models.NotificationActor{}).
+		Select("notification_id, COUNT(*) AS actors, MAX(id) AS last_id").
+		Where("notification_id IN ?", ids).
+		Group("notification_id").
+		Find(&stats).Error
+	if err != nil {
+		return err
+	}
+
+	lastIDs := make([]uint, len(stats))
+	for i, stat := range stats {
+		lastIDs[i] = stat.LastID
+	}
+	var latest []models.NotificationActor
+	if len(lastIDs) > 0 {
+		if err := tx.Where("id IN ?", lastIDs).Find(&latest).Error; err != nil {
+			return err
+		}
+	}
+	latestActor := make(map[uint]uint, len(latest))
+	for _, actor := range latest {
+		latestActor[actor.NotificationID] = actor.UserID
+	}
+
+	remaining := make(map[uint]bool, len(stats))
+	for _, stat := range stats {
+		remaining[stat.NotificationID] = true
+		err := tx.Model(&models.Notification{}).Where("id = ?", stat.NotificationID).
+			UpdateColumns(map[string]interface{}{"actor_count": stat.Actors, "actor_id": latestActor[stat.NotificationID]}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var empty []uint
+	for _, id := range ids {
+		if !remaining[id] {
+			empty = append(empty, id)
+		}
+	}
+	if len(empty) == 0 {
+		return nil
+	}
+
+	err = tx.Where("id IN ? AND type IN ?", empty, aggregatedTypes()).Delete(&models.Notification{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.Notification{}).Where("id IN ?", empty).
+		UpdateColumns(map[string]interface{}{"actor_count": 0, "actor_id": nil}).Error
+}
+
+// deleteWhere mengambil ID lebih dulu karena MySQL tidak mengizinkan DELETE
+// dengan subquery ke tabel yang sama
+func deleteWhere(tx *gorm.DB, query *gorm.DB) error {
+	var ids []uint
+	if err := query.Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+		return err
+	}
+	if err := tx.Where("notification_id IN ?", ids).Delete(&models.NotificationActor{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id IN ?", ids).Delete(&models.Notification{}).Error
+}
+
+// MarkRead menandai notifikasi hasil query sudah dibaca. Notifikasi gabungan
+// yang sudah dibaca tidak menerima event baru lagi.
+func MarkRead(query *gorm.DB, readAt time.Time) *gorm.DB {
+	return query.UpdateColumns(map[string]interface{}{"read_at": readAt, "unread_group_key": nil})
+}
+
+// unreadGroup mengunci notifikasi belum dibaca milik grup. ID bernilai 0 jika
+// belum ada.
+func unreadGroup(tx *gorm.DB, userID uint, groupKey string) (models.Notification, error) {
+	var notification models.Notification
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND unread_group_key = ?", userID, groupKey).
+		Limit(1).Find(&notification).Error
+	return notification, err
+}
+
+// aggregate menggabungkan event ke notifikasi yang belum dibaca. Pelaku
+// terakhir ditampilkan, sedangkan actor_count menghitung pelaku yang berbeda.
+func aggregate(tx *gorm.DB, notification models.Notification, actorID uint, data string) error {
+	updates := map[string]interface{}{"data": data}
+	if actorID != 0 {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.NotificationActor{NotificationID: notification.ID, UserID: actorID})
+		if result.Error != nil {
+			return result.Error
+		}
+		updates["actor_id"] = actorID
+		if result.RowsAffected > 0 {
+			updates["actor_count"] = gorm.Expr("actor_count + 1")
+		}
+	}
+	return tx.Model(&notification).Updates(updates).Error
+}
+
+// actorHidden mengembalikan true jika penerima dan pelaku saling memblokir
+// atau penerima me-mute pelaku
+func actorHidden(tx *gorm.DB, userID, actorID uint) (bool, error) {
+	var blocks int64
+	err := tx.Model(&models.Block{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userID, actorID, actorID, userID).
+		Count(&blocks).Error
+	if err != nil || blocks > 0 {
+		return blocks > 0, err
+	}
+
+	var mutes int64
+	err = tx.Model(&models.Mute{}).Where("muter_id = ? AND muted_id = ?", userID, actorID).Count(&mutes).Error
+	return mutes > 0, err
+}
+
+// Message menyusun teks notifikasi, misalnya "ana and 4 others commented on
+// your photo". Actor harus sudah dimuat.
+func Message(notification models.Notification) string {
+	actor := "Someone"
+	if notification.Actor != nil && notification.Actor.Username != "" {
+		actor = notification.Actor.Username
+	}
+	switch others := notification.ActorCount - 1; {
+	case others == 1:
+		actor += " and 1 other"
+	case others > 1:
+		actor += fmt.Sprintf(" and %d others", others)
+	}
+
+	var data struct {
+		Event      string `json:"event"`
+		PhotoTitle string `json:"photo_title"`
+	}
+	notification.DecodeData(&data)
+
+	switch notification.Type {
+	case models.NotificationCommentCreated:
+		return actor + " commented on your photo"
+	case models.NotificationCommentEdited:
+		if notification.ActorCount > 1 {
+			return actor + " edited comments on your photo"
+		}
+		return actor + " edited a comment on your photo"
+	case models.NotificationPhotoDeleted:
+		if data.PhotoTitle == "" {
+			return "A photo you commented on was deleted"
+		}
+		return fmt.Sprintf("A photo you commented on was deleted: %s", data.PhotoTitle)
+	case models.NotificationSecurity:
+		if message, ok := securityMessages[data.Event]; ok {
+			return message
+		}
+		return "Security activity on your account"
+	}
+	return ""
+}
@@ -0,0 +1,332 @@
+package notifications
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Mikael88/go-mygram/models"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func TestMessage(t *testing.T) {
+	ana := &models.User{Username: "ana"}
+	tests := []struct {
+		name         string
+		notification models.Notification
+		want         string
+	}{
+		{"single actor", models.Notification{Type: models.NotificationCommentCreated, Actor: ana, ActorCount: 1}, "ana commented on your photo"},
+		{"one other", models.Notification{Type: models.NotificationCommentCreated, Actor: ana, ActorCount: 2}, "ana and 1 other commented on your photo"},
+		{"many others", models.Notification{Type: models.NotificationCommentCreated, Actor: ana, ActorCount: 5}, "ana and 4 others commented on your photo"},
+		{"no actor loaded", models.Notification{Type: models.NotificationCommentCreated, ActorCount: 1}, "Someone commented on your photo"},
+		{"deleted actor", models.Notification{Type: models.NotificationCommentCreated, Actor: &models.User{}, ActorCount: 3}, "Someone and 2 others commented on your photo"},
+		{"edited once", models.Notification{Type: models.NotificationCommentEdited, Actor: ana, ActorCount: 1}, "ana edited a comment on your photo"},
+		{"edited by many", models.Notification{Type: models.NotificationCommentEdited, Actor: ana, ActorCount: 3}, "ana and 2 others edited comments on your photo"},
+		{"photo deleted", models.Notification{Type: models.NotificationPhotoDeleted, Data: `{"photo_title":"Sunset"}`}, "A photo you commented on was deleted: Sunset"},
+		{"photo deleted untitled", models.Notification{Type: models.NotificationPhotoDeleted}, "A photo you commented on was deleted"},
+		{"security", models.Notification{Type: models.NotificationSecurity, Data: `{"event":"password_changed"}`}, "Your password was changed"},
+		{"unknown security event", models.Notification{Type: models.NotificationSecurity, Data: `{"event":"other"}`}, "Security activity on your account"},
+		{"unknown type", models.Notification{Type: "other"}, ""},
+	}
+	for _, tt := range tests {
+		if got := Message(tt.notification); got != tt.want {
+			t.Errorf("%s: Message() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWantsNotification(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences map[string]bool
+		kind        string
+		want        bool
+	}{
+		{"no preferences", nil, models.NotificationCommentCreated, true},
+		{"disabled", map[string]bool{models.NotificationCommentCreated: false}, models.NotificationCommentCreated, false},
+		{"enabled", map[string]bool{models.NotificationCommentCreated: true}, models.NotificationCommentCreated, true},
+		{"other type disabled", map[string]bool{models.NotificationCommentEdited: false}, models.NotificationCommentCreated, true},
+		{"security cannot be disabled", map[string]bool{models.NotificationSecurity: false}, models.NotificationSecurity, true},
+	}
+	for _, tt := range tests {
+		user := models.User{NotificationPreferences: tt.preferences}
+		if got := user.WantsNotification(tt.kind); got != tt.want {
+			t.Errorf("%s: WantsNotification(%q) = %v, want %v", tt.name, tt.kind, got, tt.want)
+		}
+	}
+}
+
+// fakeDB adalah koneksi GORM dry-run yang mencatat SQL dan mengisi hasil query
+// dari respond, sehingga alur Notify bisa diuji tanpa database
+type fakeDB struct {
+	db         *gorm.DB
+	statements []string
+	// respond mengisi hasil query per tabel
+	respond map[string]func(tx *gorm.DB)
+	// conflicts adalah tabel yang insert berikutnya dianggap bentrok dengan
+	// indeks unik
+	conflicts map[string]int
+	nextID    uint
+}
+
+func newFakeDB(t *testing.T) *fakeDB {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "u:p@tcp(127.0.0.1:1)/x", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakeDB{db: db, respond: map[string]func(tx *gorm.DB){}, conflicts: map[string]int{}, nextID: 100}
+	record := func(tx *gorm.DB) {
+		f.statements = append(f.statements, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	}
+	db.Callback().Query().After("gorm:query").Register("test:query", func(tx *gorm.DB) {
+		record(tx)
+		if respond, ok := f.respond[tx.Statement.Table]; ok {
+			// Count memakai RowsAffected jika bukan 1
+			tx.RowsAffected = 1
+			respond(tx)
+		}
+	})
+	db.Callback().Create().After("gorm:create").Register("test:create", func(tx *gorm.DB) {
+		record(tx)
+		if f.conflicts[tx.Statement.Table] > 0 {
+			f.conflicts[tx.Statement.Table]--
+			return
+		}
+		tx.RowsAffected = 1
+		if field := tx.Statement.Schema.PrioritizedPrimaryField; field != nil {
+			f.nextID++
+			field.Set(tx.Statement.Context, tx.Statement.ReflectValue, f.nextID)
+		}
+	})
+	db.Callback().Update().After("gorm:update").Register("test:update", record)
+	db.Callback().Delete().After("gorm:delete").Register("test:delete", record)
+	return f
+}
+
+// has mengembalikan true jika ada statement yang memuat semua potongan SQL
+func (f *fakeDB) has(parts ...string) bool {
+	for _, statement := range f.statements {
+		matched := true
+		for _, part := range parts {
+			if !strings.Contains(statement, part) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeDB) dump(t *testing.T) {
+	t.Helper()
+	for _, statement := range f.statements {
+		t.Log(statement)
+	}
+}
+
+func recipient(preferences map[string]bool) func(tx *gorm.DB) {
+	return func(tx *gorm.DB) {
+		*tx.Statement.Dest.(*models.User) = models.User{ID: 1, NotificationPreferences: preferences}
+	}
+}
+
+func commentEvent() Event {
+	return Event{
+		UserID:     1,
+		Type:       models.NotificationCommentCreated,
+		ActorID:    2,
+		TargetType: models.NotificationTargetPhoto,
+		TargetID:   9,
+	}
+}
+
+func TestNotifySkipped(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   Event
+		respond map[string]func(tx *gorm.DB)
+	}{
+		{"own action", Event{UserID: 1, Type: models.NotificationCommentCreated, ActorID: 1}, nil},
+		{"deleted recipient", commentEvent(), map[string]func(tx *gorm.DB){
+			"users": func(tx *gorm.DB) { tx.AddError(gorm.ErrRecordNotFound) },
+		}},
+		{"opted out", commentEvent(), map[string]func(tx *gorm.DB){
+			"users": recipient(map[string]bool{models.NotificationCommentCreated: false}),
+		}},
+		{"blocked", commentEvent(), map[string]func(tx *gorm.DB){
+			"users":  recipient(nil),
+			"blocks": func(tx *gorm.DB) { *tx.Statement.Dest.(*int64) = 1 },
+		}},
+		{"muted", commentEvent(), map[string]func(tx *gorm.DB){
+			"users": recipient(nil),
+			"mutes": func(tx *gorm.DB) { *tx.Statement.Dest.(*int64) = 1 },
+		}},
+	}
+	for _, tt := range tests {
+		f := newFakeDB(t)
+		for table, respond := range tt.respond {
+			f.respond[table] = respond
+		}
+		if err := Notify(f.db, tt.event); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if f.has("INSERT") || f.has("UPDATE") {
+			f.dump(t)
+			t.Errorf("%s: notification was recorded", tt.name)
+		}
+	}
+}
+
+func TestNotifyCreatesGroup(t *testing.T) {
+	f := newFakeDB(t)
+	f.respond["users"] = recipient(nil)
+
+	if err := Notify(f.db, commentEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if !f.has("FROM `notifications` WHERE user_id = 1 AND unread_group_key = 'comment_created:photo:9'", "FOR UPDATE") {
+		f.dump(t)
+		t.Fatal("unread group was not looked up with a lock")
+	}
+	if !f.has("INSERT INTO `notifications`", "'comment_created:photo:9'", "ON DUPLICATE KEY UPDATE") {
+		f.dump(t)
+		t.Fatal("notification was not inserted with its unread group key")
+	}
+	if !f.has("INSERT INTO `notification_actors`", "(101,2") {
+		f.dump(t)
+		t.Fatal("actor was not recorded for the new notification")
+	}
+}
+
+func TestNotifyAggregatesUnreadGroup(t *testing.T) {
+	tests := []struct {
+		name     string
+		newActor bool
+	}{
+		{"new actor", true},
+		{"repeat actor", false},
+	}
+	for _, tt := range tests {
+		f := newFakeDB(t)
+		f.respond["users"] = recipient(nil)
+		f.respond["notifications"] = func(tx *gorm.DB) {
+			*tx.Statement.Dest.(*models.Notification) = models.Notification{ID: 5, UserID: 1, ActorCount: 3}
+		}
+		if !tt.newActor {
+			f.conflicts["notification_actors"] = 1
+		}
+
+		if err := Notify(f.db, commentEvent()); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if f.has("INSERT INTO `notifications`") {
+			f.dump(t)
+			t.Fatalf("%s: a second unread notification was created", tt.name)
+		}
+		if !f.has("INSERT INTO `notification_actors`", "(5,2", "ON DUPLICATE KEY UPDATE") {
+			f.dump(t)
+			t.Fatalf("%s: actor was not added to the group", tt.name)
+		}
+		if !f.has("UPDATE `notifications` SET", "`actor_id`=2", "WHERE `id` = 5") {
+			f.dump(t)
+			t.Fatalf("%s: latest actor was not updated", tt.name)
+		}
+		if got := f.has("`actor_count`=actor_count + 1"); got != tt.newActor {
+			f.dump(t)
+			t.Errorf("%s: actor_count incremented = %v, want %v", tt.name, got, tt.newActor)
+		}
+	}
+}
+
+func TestNotifyJoinsConcurrentGroup(t *testing.T) {
+	f := newFakeDB(t)
+	f.respond["users"] = recipient(nil)
+	lookups := 0
+	f.respond["notifications"] = func(tx *gorm.DB) {
+		// Grup belum ada saat dicari pertama kali, lalu sudah dibuat transaksi lain
+		lookups++
+		if lookups > 1 {
+			*tx.Statement.Dest.(*models.Notification) = models.Notification{ID: 8, UserID: 1, ActorCount: 1}
+		}
+	}
+	f.conflicts["notifications"] = 1
+
+	if err := Notify(f.db, commentEvent()); err != nil {
+		t.Fatal(err)
+	}
+	if lookups != 2 {
+		t.Fatalf("unread group looked up %d times, want 2", lookups)
+	}
+	if !f.has("INSERT INTO `notification_actors`", "(8,2") || !f.has("UPDATE `notifications` SET", "WHERE `id` = 8") {
+		f.dump(t)
+		t.Fatal("event was not merged into the concurrently created group")
+	}
+}
+
+func TestNotifyUngroupedEvent(t *testing.T) {
+	f := newFakeDB(t)
+	f.respond["users"] = recipient(nil)
+
+	err := Notify(f.db, Event{UserID: 1, Type: models.NotificationPhotoDeleted, ActorID: 2, TargetType: models.NotificationTargetPhoto, TargetID: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.has("unread_group_key = ") {
+		f.dump(t)
+		t.Fatal("ungrouped event looked up an unread group")
+	}
+	if !f.has("INSERT INTO `notifications`", "'photo_deleted'", "NULL") {
+		f.dump(t)
+		t.Fatal("notification was not inserted without a group")
+	}
+}
+
+func TestRemoveActorRecounts(t *testing.T) {
+	f := newFakeDB(t)
+	f.respond["notifications"] = func(tx *gorm.DB) {
+		*tx.Statement.Dest.(*[]uint) = []uint{1, 2}
+	}
+	f.respond["notification_actors"] = func(tx *gorm.DB) {
+		switch dest := tx.Statement.Dest.(type) {
+		case *[]struct {
+			NotificationID uint
+			Actors         int
+			LastID         uint
+		}:
+			// Notifikasi 1 masih punya dua pelaku, notifikasi 2 tidak punya lagi
+			*dest = append(*dest, struct {
+				NotificationID uint
+				Actors         int
+				LastID         uint
+			}{NotificationID: 1, Actors: 2, LastID: 40})
+		case *[]models.NotificationActor:
+			*dest = []models.NotificationActor{{ID: 40, NotificationID: 1, UserID: 6}}
+		}
+	}
+
+	if err := RemoveActor(f.db, models.NotificationTargetPhoto, 9, 2); err != nil {
+		t.Fatal(err)
+	}
+	if !f.has("DELETE FROM `notification_actors` WHERE notification_id IN (1,2) AND user_id = 2") {
+		f.dump(t)
+		t.Fatal("actor rows were not removed")
+	}
+	if !f.has("UPDATE `notifications` SET", "`actor_count`=2", "`actor_id`=6", "WHERE id = 1") {
+		f.dump(t)
+		t.Fatal("remaining actors were not recounted")
+	}
+	if !f.has("DELETE FROM `notifications` WHERE id IN (2) AND type IN") {
+		f.dump(t)
+		t.Fatal("aggregated notification without actors was not deleted")
+	}
+	if !f.has("UPDATE `notifications` SET", "`actor_count`=0", "`actor_id`=NULL", "WHERE id IN (2)") {
+		f.dump(t)
+		t.Fatal("actor was not cleared from notification without actors")
+	}
+}
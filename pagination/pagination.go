@@ -0,0 +1,270 @@
+// Package pagination menyediakan paginasi, pengurutan, dan filter yang sama
+// untuk semua endpoint daftar.
+//
+// Dua mode didukung:
+//   - cursor: ?cursor=<next_cursor dari halaman sebelumnya>&limit=20
+//   - offset: ?offset=40&limit=20
+//
+// Urutan dipilih dengan ?sort=created_at (naik) atau ?sort=-created_at (turun).
+// Setiap halaman selalu mengembalikan next_cursor jika masih ada data, sehingga
+// klien mode offset pun bisa beralih ke cursor.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidLimit  = fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+	ErrInvalidOffset = errors.New("offset must be a non-negative number")
+)
+
+// Sort adalah kolom yang boleh dipakai untuk mengurutkan item bertipe T.
+// Key mengembalikan nilai kolom tersebut dari item, dipakai untuk membuat cursor.
+type Sort[T any] struct {
+	Column string
+	Key    func(T) any
+}
+
+// Options mendefinisikan parameter apa saja yang diterima sebuah endpoint daftar.
+type Options[T any] struct {
+	// Sorts memetakan nama di query ?sort= ke kolomnya
+	Sorts map[string]Sort[T]
+	// DefaultSort misalnya "-created_at"
+	DefaultSort string
+	// IDColumn dipakai sebagai pemecah seri, default "id"
+	IDColumn string
+	// ID mengembalikan ID item, dipakai bersama Sort.Key untuk cursor
+	ID func(T) uint
+	// Filters memetakan query parameter ke kolom yang difilter dengan kesamaan ID,
+	// misalnya "user_id" -> "user_id"
+	Filters map[string]string
+	// CreatedColumn dipakai untuk filter created_after dan created_before.
+	// Kosongkan untuk menonaktifkan filter rentang waktu.
+	CreatedColumn string
+}
+
+// Params adalah hasil parsing query parameter untuk satu request.
+type Params[T any] struct {
+	Limit  int
+	Offset int
+
+	opts     Options[T]
+	sortName string
+	sort     Sort[T]
+	desc     bool
+	cursor   *cursor
+	filters  map[string]uint64
+	after    *time.Time
+	before   *time.Time
+}
+
+// Response adalah envelope standar untuk endpoint daftar.
+type Response struct {
+	Data       any     `json:"data"`
+	NextCursor *string `json:"next_cursor"`
+	Limit      int     `json:"limit"`
+	Offset     *int    `json:"offset,omitempty"`
+}
+
+type cursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    uint            `json:"id"`
+}
+
+// Parse membaca limit, cursor/offset, sort, dan filter dari query string.
+func Parse[T any](c *gin.Context, opts Options[T]) (Params[T], error) {
+	p := Params[T]{Limit: DefaultLimit, opts: opts, filters: map[string]uint64{}}
+	if p.opts.IDColumn == "" {
+		p.opts.IDColumn = "id"
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return p, ErrInvalidLimit
+		}
+		p.Limit = limit
+	}
+
+	sortName := c.DefaultQuery("sort", opts.DefaultSort)
+	p.desc = strings.HasPrefix(sortName, "-")
+	sort, ok := opts.Sorts[strings.TrimPrefix(sortName, "-")]
+	if !ok {
+		return p, fmt.Errorf("unsupported sort %q", sortName)
+	}
+	p.sortName = sortName
+	p.sort = sort
+
+	if raw := c.Query("cursor"); raw != "" {
+		if c.Query("offset") != "" {
+			return p, errors.New("cursor and offset cannot be combined")
+		}
+		cur, err := decodeCursor(raw)
+		if err != nil || cur.Sort != sortName {
+			return p, ErrInvalidCursor
+		}
+		p.cursor = &cur
+	} else if raw := c.Query("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return p, ErrInvalidOffset
+		}
+		p.Offset = offset
+	}
+
+	for param := range opts.Filters {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return p, fmt.Errorf("%s must be a numeric ID", param)
+		}
+		p.filters[param] = id
+	}
+
+	if opts.CreatedColumn != "" {
+		var err error
+		if p.after, err = parseTime(c, "created_after"); err != nil {
+			return p, err
+		}
+		if p.before, err = parseTime(c, "created_before"); err != nil {
+			return p, err
+		}
+	}
+
+	return p, nil
+}
+
+// Find menjalankan query dengan filter, urutan, dan batas halaman lalu mengisi
+// dest. Cursor halaman berikutnya dikembalikan jika masih ada data.
+func (p Params[T]) Find(query *gorm.DB, dest *[]T) (*string, error) {
+	query = p.Scope(query)
+	if p.cursor != nil {
+		value, err := p.cursorValue()
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		op := ">"
+		if p.desc {
+			op = "<"
+		}
+		query = query.Where(
+			fmt.Sprintf("(%[1]s %[3]s ? OR (%[1]s = ? AND %[2]s %[3]s ?))", p.sort.Column, p.opts.IDColumn, op),
+			value, value, p.cursor.ID,
+		)
+	} else if p.Offset > 0 {
+		query = query.Offset(p.Offset)
+	}
+
+	direction := ""
+	if p.desc {
+		direction = " DESC"
+	}
+	query = query.Order(p.sort.Column + direction).Order(p.opts.IDColumn + direction)
+
+	// Ambil satu item lebih untuk mengetahui apakah masih ada halaman berikutnya
+	if err := query.Limit(p.Limit + 1).Find(dest).Error; err != nil {
+		return nil, err
+	}
+
+	if len(*dest) <= p.Limit {
+		return nil, nil
+	}
+	*dest = (*dest)[:p.Limit]
+
+	last := (*dest)[p.Limit-1]
+	next, err := encodeCursor(p.sortName, p.sort.Key(last), p.opts.ID(last))
+	if err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// Scope menerapkan filter dari query string tanpa urutan dan batas halaman.
+func (p Params[T]) Scope(query *gorm.DB) *gorm.DB {
+	for param, id := range p.filters {
+		query = query.Where(p.opts.Filters[param]+" = ?", id)
+	}
+	if p.after != nil {
+		query = query.Where(p.opts.CreatedColumn+" >= ?", *p.after)
+	}
+	if p.before != nil {
+		query = query.Where(p.opts.CreatedColumn+" < ?", *p.before)
+	}
+	return query
+}
+
+// Response membungkus data halaman ini ke dalam envelope standar.
+func (p Params[T]) Response(data any, next *string) Response {
+	res := Response{Data: data, NextCursor: next, Limit: p.Limit}
+	if p.cursor == nil && p.Offset > 0 {
+		offset := p.Offset
+		res.Offset = &offset
+	}
+	return res
+}
+
+// cursorValue mengubah nilai cursor kembali ke tipe kolom urutan agar
+// perbandingan di database memakai tipe yang sama.
+func (p Params[T]) cursorValue() (any, error) {
+	var zero T
+	value := reflect.New(reflect.TypeOf(p.sort.Key(zero)))
+	if err := json.Unmarshal(p.cursor.Value, value.Interface()); err != nil {
+		return nil, err
+	}
+	return value.Elem().Interface(), nil
+}
+
+func encodeCursor(sort string, value any, id uint) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(cursor{Sort: sort, Value: raw, ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(raw string) (cursor, error) {
+	var cur cursor
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cur, err
+	}
+	err = json.Unmarshal(data, &cur)
+	return cur, err
+}
+
+func parseTime(c *gin.Context, param string) (*time.Time, error) {
+	raw := c.Query(param)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", param)
+	}
+	return &t, nil
+}
@@ -0,0 +1,169 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+type item struct {
+	ID        uint
+	CreatedAt time.Time
+	Score     int
+}
+
+var itemPage = Options[item]{
+	Sorts: map[string]Sort[item]{
+		"created_at": {Column: "created_at", Key: func(i item) any { return i.CreatedAt }},
+		"score":      {Column: "score", Key: func(i item) any { return i.Score }},
+	},
+	DefaultSort:   "-created_at",
+	ID:            func(i item) uint { return i.ID },
+	Filters:       map[string]string{"user_id": "user_id"},
+	CreatedColumn: "created_at",
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func testContext(query string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/items?"+query, nil)
+	return c
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	raw, err := encodeCursor("-created_at", createdAt, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ContainsAny(raw, "+/=") {
+		t.Errorf("cursor %q is not URL safe", raw)
+	}
+
+	p, err := Parse(testContext("cursor="+raw), itemPage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.cursor.ID != 42 {
+		t.Errorf("cursor ID = %d, want 42", p.cursor.ID)
+	}
+	value, err := p.cursorValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := value.(time.Time); !ok || !got.Equal(createdAt) {
+		t.Errorf("cursor value = %#v, want %v", value, createdAt)
+	}
+
+	raw, _ = encodeCursor("score", 17, 3)
+	p, err = Parse(testContext("sort=score&cursor="+raw), itemPage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := p.cursorValue(); value != 17 {
+		t.Errorf("int cursor value = %#v, want 17", value)
+	}
+}
+
+func TestParseRejectsInvalidInput(t *testing.T) {
+	otherSort, _ := encodeCursor("created_at", time.Now(), 1)
+	tests := map[string]string{
+		"bad limit":         "limit=0",
+		"limit too large":   "limit=101",
+		"negative offset":   "offset=-1",
+		"unknown sort":      "sort=password",
+		"garbage cursor":    "cursor=not-base64!",
+		"non-json cursor":   "cursor=aGVsbG8",
+		"cursor other sort": "cursor=" + otherSort,
+		"cursor and offset": "cursor=" + otherSort + "&sort=created_at&offset=1",
+		"non-numeric id":    "user_id=abc",
+		"bad timestamp":     "created_after=yesterday",
+	}
+	for name, query := range tests {
+		if _, err := Parse(testContext(query), itemPage); err == nil {
+			t.Errorf("%s: Parse(%q) succeeded", name, query)
+		}
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	p, err := Parse(testContext(""), itemPage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Limit != DefaultLimit || !p.desc || p.sortName != "-created_at" || p.opts.IDColumn != "id" {
+		t.Errorf("defaults = %+v", p)
+	}
+
+	res := p.Response([]item{}, nil)
+	if res.Offset != nil || res.Limit != DefaultLimit {
+		t.Errorf("Response = %+v", res)
+	}
+
+	p, _ = Parse(testContext("offset=40&limit=10"), itemPage)
+	if res := p.Response(nil, nil); res.Offset == nil || *res.Offset != 40 || res.Limit != 10 {
+		t.Errorf("offset Response = %+v", res)
+	}
+}
+
+// findSQL menjalankan Find dalam mode dry run dan mengembalikan SQL yang dibuat
+func findSQL(t *testing.T, query string) (string, []interface{}) {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "u:p@tcp(127.0.0.1:1)/x", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sql string
+	var vars []interface{}
+	db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		sql = tx.Statement.SQL.String()
+		vars = tx.Statement.Vars
+	})
+
+	p, err := Parse(testContext(query), itemPage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []item
+	if _, err := p.Find(db.Table("items"), &items); err != nil {
+		t.Fatal(err)
+	}
+	return sql, vars
+}
+
+func TestFindKeyset(t *testing.T) {
+	raw, _ := encodeCursor("-created_at", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 9)
+	sql, vars := findSQL(t, "cursor="+raw+"&limit=5&user_id=3")
+
+	for _, part := range []string{
+		"user_id = ?",
+		"(created_at < ? OR (created_at = ? AND id < ?))",
+		"ORDER BY created_at DESC,id DESC",
+		"LIMIT ?",
+	} {
+		if !strings.Contains(sql, part) {
+			t.Errorf("SQL %q does not contain %q", sql, part)
+		}
+	}
+	if strings.Contains(sql, "OFFSET") {
+		t.Errorf("cursor query uses OFFSET: %s", sql)
+	}
+	// Satu item lebih diambil untuk mengetahui apakah ada halaman berikutnya
+	if len(vars) != 5 || vars[0] != uint64(3) || vars[3] != uint(9) || vars[4] != 6 {
+		t.Errorf("vars = %v", vars)
+	}
+
+	sql, vars = findSQL(t, "sort=score&offset=20")
+	if !strings.Contains(sql, "ORDER BY score,id LIMIT ? OFFSET ?") || len(vars) != 2 || vars[0] != 21 || vars[1] != 20 {
+		t.Errorf("offset SQL = %s %v", sql, vars)
+	}
+}
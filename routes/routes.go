@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"github.com/Mikael88/go-mygram/auth"
 	"github.com/Mikael88/go-mygram/controllers"
 	"github.com/Mikael88/go-mygram/middlewares"
 	"github.com/gin-gonic/gin"
@@ -9,25 +10,113 @@ import (
 func SetupRoutes(r *gin.Engine) {
 	r.POST("/register", controllers.RegisterUser)
 	r.POST("/login", controllers.LoginUser)
+	r.POST("/login/2fa", controllers.LoginTwoFactor)
+	r.POST("/token/refresh", controllers.RefreshToken)
+	r.POST("/logout", middlewares.AuthMiddleware(), controllers.LogoutUser)
+	r.GET("/.well-known/jwks.json", controllers.GetJWKS)
+	r.POST("/password/forgot", controllers.ForgotPassword)
+	r.POST("/password/reset", controllers.ResetPassword)
+	r.GET("/verify-email", controllers.VerifyEmail)
+	r.GET("/media/*key", controllers.ServeMedia)
 
 	api := r.Group("/api")
 	api.Use(middlewares.AuthMiddleware()) // Terapkan middleware AuthMiddleware pada grup api
 
-	api.POST("/photos", controllers.CreatePhoto)
-	api.GET("/photos", controllers.GetPhotos)
-	api.PUT("/photos/:photoId", middlewares.AuthorizePhoto(), controllers.UpdatePhoto)
-	api.DELETE("/photos/:photoId", middlewares.AuthorizePhoto(), controllers.DeletePhoto)
+	// Setiap grup mendeklarasikan scope yang dibutuhkan personal access token
+	// Hasil pencarian disaring sendiri sesuai scope token
+	api.GET("/search", controllers.GetSearch)
 
-	api.POST("/comments", controllers.CreateComment)
-	api.GET("/comments", controllers.GetComments)
-	api.PUT("/comments/:commentId", middlewares.AuthorizeComment(), controllers.UpdateComment)
-	api.DELETE("/comments/:commentId", middlewares.AuthorizeComment(), controllers.DeleteComment)
+	photosRead := api.Group("", middlewares.RequireScope(auth.ScopePhotosRead))
+	photosRead.GET("/photos", controllers.GetPhotos)
+	photosRead.GET("/feed", controllers.GetFeed)
+	photosRead.GET("/scheduled-photos", controllers.GetScheduledPhotos)
+	photosRead.GET("/photos/:photoId", controllers.GetPhoto)
+	photosRead.GET("/photos/:photoId/likes", controllers.GetPhotoLikes)
+	photosRead.GET("/tags/trending", controllers.GetTrendingTags)
+	photosRead.GET("/tags/:tag/photos", controllers.GetTagPhotos)
 
-	api.POST("/socialmedias", controllers.CreateSocialMedia)
-	api.GET("/socialmedias", controllers.GetSocialMedias)
-	api.PUT("/socialmedias/:socialMediaId", middlewares.AuthorizeSocialMedia(), controllers.UpdateSocialMedia)
-	api.DELETE("/socialmedias/:socialMediaId", middlewares.AuthorizeSocialMedia(), controllers.DeleteSocialMedia)
+	photosWrite := api.Group("", middlewares.RequireScope(auth.ScopePhotosWrite))
+	photosWrite.POST("/photos", middlewares.RequireVerifiedEmail(), controllers.CreatePhoto)
+	photosWrite.PUT("/photos/:photoId", middlewares.AuthorizeOwner(middlewares.PhotoLoader, "photoId"), controllers.UpdatePhoto)
+	photosWrite.PUT("/photos/:photoId/location", middlewares.AuthorizeOwner(middlewares.PhotoLoader, "photoId"), controllers.UpdatePhotoLocation)
+	photosWrite.POST("/photos/:photoId/publish", middlewares.AuthorizeOwner(middlewares.PhotoLoader, "photoId"), controllers.PublishPhoto)
+	photosWrite.POST("/photos/:photoId/archive", middlewares.AuthorizeOwner(middlewares.PhotoLoader, "photoId"), controllers.ArchivePhoto)
+	photosWrite.POST("/photos/:photoId/unarchive", middlewares.AuthorizeOwner(middlewares.PhotoLoader, "photoId"), controllers.UnarchivePhoto)
+	photosWrite.PUT("/photos/:photoId/schedule", middlewares.AuthorizeOwner(middlewares.PhotoLoader, "photoId"), controllers.SchedulePhoto)
+	photosWrite.DELETE("/photos/:photoId/schedule", middlewares.AuthorizeOwner(middlewares.PhotoLoader, "photoId"), controllers.UnschedulePhoto)
+	photosWrite.POST("/photos/:photoId/like", controllers.LikePhoto)
+	photosWrite.DELETE("/photos/:photoId/like", controllers.UnlikePhoto)
+	photosWrite.DELETE("/photos/:photoId", middlewares.AuthorizeOwner(middlewares.PhotoLoader, "photoId", middlewares.GrantPermission(auth.PermModeratePhotos)), controllers.DeletePhoto)
 
-	api.PUT("/users", middlewares.AuthMiddleware(), controllers.UpdateUser)
-	api.DELETE("/users", middlewares.AuthMiddleware(), controllers.DeleteUser)
+	commentsRead := api.Group("", middlewares.RequireScope(auth.ScopeCommentsRead))
+	commentsRead.GET("/comments", controllers.GetComments)
+	commentsRead.GET("/photos/:photoId/comments", controllers.GetPhotoComments)
+	commentsRead.GET("/comments/:commentId/replies", controllers.GetCommentReplies)
+	commentsRead.GET("/comments/:commentId/likes", controllers.GetCommentLikes)
+
+	commentsWrite := api.Group("", middlewares.RequireScope(auth.ScopeCommentsWrite))
+	commentsWrite.POST("/comments", middlewares.RequireVerifiedEmail(), controllers.CreateComment)
+	commentsWrite.PUT("/comments/:commentId", middlewares.AuthorizeOwner(middlewares.CommentLoader, "commentId"), controllers.UpdateComment)
+	commentsWrite.POST("/comments/:commentId/like", controllers.LikeComment)
+	commentsWrite.DELETE("/comments/:commentId/like", controllers.UnlikeComment)
+	commentsWrite.DELETE("/comments/:commentId", middlewares.AuthorizeOwner(middlewares.CommentLoader, "commentId", middlewares.GrantPermission(auth.PermModerateComments)), controllers.DeleteComment)
+
+	socialMediasRead := api.Group("", middlewares.RequireScope(auth.ScopeSocialMediasRead))
+	socialMediasRead.GET("/socialmedias", controllers.GetSocialMedias)
+
+	socialMediasWrite := api.Group("", middlewares.RequireScope(auth.ScopeSocialMediasWrite))
+	socialMediasWrite.POST("/socialmedias", controllers.CreateSocialMedia)
+	socialMediasWrite.PUT("/socialmedias/:socialMediaId", middlewares.AuthorizeOwner(middlewares.SocialMediaLoader, "socialMediaId"), controllers.UpdateSocialMedia)
+	socialMediasWrite.DELETE("/socialmedias/:socialMediaId", middlewares.AuthorizeOwner(middlewares.SocialMediaLoader, "socialMediaId", middlewares.GrantPermission(auth.PermManageUsers)), controllers.DeleteSocialMedia)
+
+	usersRead := api.Group("", middlewares.RequireScope(auth.ScopeUsersRead))
+	usersRead.GET("/users/:userId/profile", controllers.GetUserProfile)
+	usersRead.GET("/users/:userId/followers", controllers.GetFollowers)
+	usersRead.GET("/users/:userId/following", controllers.GetFollowing)
+	usersRead.GET("/access-requests", controllers.GetAccessRequests)
+	usersRead.GET("/blocks", controllers.GetBlockedUsers)
+	usersRead.GET("/mutes", controllers.GetMutedUsers)
+
+	usersWrite := api.Group("", middlewares.RequireScope(auth.ScopeUsersWrite))
+	usersWrite.POST("/users/:userId/follow", controllers.FollowUser)
+	usersWrite.DELETE("/users/:userId/follow", controllers.UnfollowUser)
+	usersWrite.POST("/users/:userId/access-requests", controllers.RequestAccess)
+	usersWrite.DELETE("/users/:userId/access-requests", controllers.CancelAccessRequest)
+	usersWrite.POST("/users/:userId/block", controllers.BlockUser)
+	usersWrite.DELETE("/users/:userId/block", controllers.UnblockUser)
+	usersWrite.POST("/users/:userId/mute", controllers.MuteUser)
+	usersWrite.DELETE("/users/:userId/mute", controllers.UnmuteUser)
+	usersWrite.POST("/access-requests/:requestId/approve", middlewares.AuthorizeOwner(middlewares.AccessRequestLoader, "requestId"), controllers.ApproveAccessRequest)
+	usersWrite.POST("/access-requests/:requestId/reject", middlewares.AuthorizeOwner(middlewares.AccessRequestLoader, "requestId"), controllers.RejectAccessRequest)
+	usersWrite.DELETE("/access-requests/:requestId", middlewares.AuthorizeOwner(middlewares.AccessRequestLoader, "requestId"), controllers.RevokeAccessRequest)
+
+	notificationsRead := api.Group("", middlewares.RequireScope(auth.ScopeNotificationsRead))
+	notificationsRead.GET("/notifications", controllers.GetNotifications)
+	notificationsRead.GET("/notifications/unread-count", controllers.GetUnreadNotificationCount)
+	notificationsRead.GET("/notifications/preferences", controllers.GetNotificationPreferences)
+
+	notificationsWrite := api.Group("", middlewares.RequireScope(auth.ScopeNotificationsWrite))
+	notificationsWrite.POST("/notifications/read-all", controllers.MarkAllNotificationsRead)
+	notificationsWrite.POST("/notifications/:notificationId/read", middlewares.AuthorizeOwner(middlewares.NotificationLoader, "notificationId"), controllers.MarkNotificationRead)
+	notificationsWrite.PUT("/notifications/preferences", controllers.UpdateNotificationPreferences)
+
+	// Pengelolaan akun dan token tidak bisa dilakukan dengan personal access token
+	account := api.Group("", middlewares.RequireSession())
+	account.PUT("/users", middlewares.AuthMiddleware(), controllers.UpdateUser)
+	account.DELETE("/users", middlewares.AuthMiddleware(), controllers.DeleteUser)
+	account.PUT("/users/privacy", controllers.UpdatePrivacy)
+	account.POST("/users/verify-email/resend", controllers.ResendEmailVerification)
+	account.POST("/users/2fa/setup", controllers.SetupTwoFactor)
+	account.POST("/users/2fa/confirm", controllers.ConfirmTwoFactor)
+	account.POST("/users/2fa/disable", controllers.DisableTwoFactor)
+
+	account.POST("/tokens", controllers.CreatePersonalAccessToken)
+	account.GET("/tokens", controllers.GetPersonalAccessTokens)
+	account.DELETE("/tokens/:tokenId", controllers.RevokePersonalAccessToken)
+
+	// Pengelolaan user oleh admin
+	admin := account.Group("/admin", middlewares.RequirePermission(auth.PermManageUsers))
+	admin.GET("/users", controllers.AdminGetUsers)
+	admin.PUT("/users/:userId/role", controllers.AdminUpdateUserRole)
+	admin.DELETE("/users/:userId", controllers.AdminDeleteUser)
 }
\ No newline at end of file
@@ -0,0 +1,183 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Mikael88/go-mygram/models"
+	"gorm.io/gorm"
+)
+
+// MemoryIndex adalah inverted index di memori. Setiap kata di query harus
+// cocok, boleh sebagai awalan, dan skor dokumen adalah jumlah kemunculan
+// kata yang cocok. Perubahan dari transaksi yang di-rollback tetap tercatat,
+// tetapi hasil pencarian selalu disaring lagi lewat database.
+type MemoryIndex struct {
+	mu sync.RWMutex
+	// terms menyimpan kata per dokumen agar dokumen bisa dihapus dari postings
+	terms map[string]map[uint][]string
+	// postings memetakan kata ke dokumen dan jumlah kemunculannya
+	postings map[string]map[string]map[uint]int
+}
+
+// NewMemoryIndex membuat MemoryIndex kosong
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		terms:    map[string]map[uint][]string{},
+		postings: map[string]map[string]map[uint]int{},
+	}
+}
+
+// Rebuild mengisi ulang index dari semua user, foto, dan komentar di database
+func (m *MemoryIndex) Rebuild(db *gorm.DB) error {
+	var users []models.User
+	err := db.Select("id", "username").FindInBatches(&users, 500, func(tx *gorm.DB, batch int) error {
+		for _, user := range users {
+			m.Index(tx, models.SearchKindUser, user.ID, user.SearchText())
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	var photos []models.Photo
+	err = db.Select("id", "title", "caption").FindInBatches(&photos, 500, func(tx *gorm.DB, batch int) error {
+		for _, photo := range photos {
+			m.Index(tx, models.SearchKindPhoto, photo.ID, photo.SearchText())
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	var comments []models.Comment
+	return db.Select("id", "message").FindInBatches(&comments, 500, func(tx *gorm.DB, batch int) error {
+		for _, comment := range comments {
+			m.Index(tx, models.SearchKindComment, comment.ID, comment.SearchText())
+		}
+		return nil
+	}).Error
+}
+
+func (m *MemoryIndex) Index(tx *gorm.DB, kind string, id uint, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(kind, id)
+
+	terms := Terms(text)
+	if len(terms) == 0 {
+		return nil
+	}
+	if m.terms[kind] == nil {
+		m.terms[kind] = map[uint][]string{}
+		m.postings[kind] = map[string]map[uint]int{}
+	}
+	m.terms[kind][id] = terms
+	for _, term := range terms {
+		if m.postings[kind][term] == nil {
+			m.postings[kind][term] = map[uint]int{}
+		}
+		m.postings[kind][term][id]++
+	}
+	return nil
+}
+
+func (m *MemoryIndex) Remove(tx *gorm.DB, kind string, id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(kind, id)
+	return nil
+}
+
+func (m *MemoryIndex) remove(kind string, id uint) {
+	for _, term := range m.terms[kind][id] {
+		delete(m.postings[kind][term], id)
+		if len(m.postings[kind][term]) == 0 {
+			delete(m.postings[kind], term)
+		}
+	}
+	delete(m.terms[kind], id)
+}
+
+func (m *MemoryIndex) Search(scoped *gorm.DB, kind, query string, limit int) ([]uint, error) {
+	table, ok := tables[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown search kind %q", kind)
+	}
+
+	terms := Terms(query)
+	if len(terms) == 0 {
+		return []uint{}, nil
+	}
+
+	ranked := m.rank(kind, terms)
+	if len(ranked) == 0 {
+		return []uint{}, nil
+	}
+
+	var visible []uint
+	if err := scoped.Where(table.table+".id IN ?", ranked).Pluck(table.table+".id", &visible).Error; err != nil {
+		return nil, err
+	}
+	allowed := make(map[uint]bool, len(visible))
+	for _, id := range visible {
+		allowed[id] = true
+	}
+
+	ids := make([]uint, 0, limit)
+	for _, id := range ranked {
+		if allowed[id] && len(ids) < limit {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// rank mengembalikan dokumen yang memuat semua kata, skor tertinggi lebih dulu
+func (m *MemoryIndex) rank(kind string, terms []string) []uint {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var scores map[uint]int
+	for _, term := range terms {
+		matched := map[uint]int{}
+		for indexed, postings := range m.postings[kind] {
+			if !strings.HasPrefix(indexed, term) {
+				continue
+			}
+			for id, count := range postings {
+				matched[id] += count
+			}
+		}
+
+		if scores == nil {
+			scores = matched
+			continue
+		}
+		for id := range scores {
+			if matched[id] == 0 {
+				delete(scores, id)
+			} else {
+				scores[id] += matched[id]
+			}
+		}
+	}
+
+	ranked := make([]uint, 0, len(scores))
+	for id := range scores {
+		ranked = append(ranked, id)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if scores[ranked[i]] != scores[ranked[j]] {
+			return scores[ranked[i]] > scores[ranked[j]]
+		}
+		return ranked[i] > ranked[j]
+	})
+	return ranked
+}
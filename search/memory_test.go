@@ -0,0 +1,162 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Mikael88/go-mygram/models"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func testIndex(docs map[uint]string) *MemoryIndex {
+	index := NewMemoryIndex()
+	for id, text := range docs {
+		index.Index(nil, models.SearchKindPhoto, id, text)
+	}
+	return index
+}
+
+func TestMemoryRank(t *testing.T) {
+	index := testIndex(map[uint]string{
+		1: "Sunset at the beach",
+		2: "sunny beach, sunny day, sunset",
+		3: "Mountain sunrise",
+		4: "beach volleyball",
+		5: "Café in Paris",
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		want  []uint
+	}{
+		{"exact term", "volleyball", []uint{4}},
+		{"prefix", "sun", []uint{2, 3, 1}},
+		{"prefix matches several words in one document", "sunn", []uint{2}},
+		{"all terms required", "sunset beach", []uint{2, 1}},
+		{"missing term excludes", "sunset mountain", []uint{}},
+		{"case folded, ties newest first", "BEACH", []uint{4, 2, 1}},
+		{"accents kept", "cafe", []uint{}},
+		{"accented", "CAFÉ", []uint{5}},
+		{"no match", "snow", []uint{}},
+		{"prefix is not infix", "set", []uint{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := index.rank(models.SearchKindPhoto, Terms(tt.query))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rank(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryIndexKinds(t *testing.T) {
+	index := NewMemoryIndex()
+	index.Index(nil, models.SearchKindPhoto, 1, "beach")
+	index.Index(nil, models.SearchKindComment, 1, "lovely beach")
+
+	if got := index.rank(models.SearchKindUser, []string{"beach"}); len(got) != 0 {
+		t.Errorf("user search found %v", got)
+	}
+	if got := index.rank(models.SearchKindComment, []string{"lovely"}); !reflect.DeepEqual(got, []uint{1}) {
+		t.Errorf("comment search = %v", got)
+	}
+	if got := index.rank(models.SearchKindPhoto, []string{"lovely"}); len(got) != 0 {
+		t.Errorf("photo search found comment text: %v", got)
+	}
+}
+
+func TestMemoryIndexReplaceAndRemove(t *testing.T) {
+	index := testIndex(map[uint]string{1: "old caption", 2: "old"})
+
+	index.Index(nil, models.SearchKindPhoto, 1, "new caption")
+	if got := index.rank(models.SearchKindPhoto, []string{"old"}); !reflect.DeepEqual(got, []uint{2}) {
+		t.Errorf("old terms still indexed: %v", got)
+	}
+	if got := index.rank(models.SearchKindPhoto, []string{"new"}); !reflect.DeepEqual(got, []uint{1}) {
+		t.Errorf("new terms not indexed: %v", got)
+	}
+
+	index.Remove(nil, models.SearchKindPhoto, 2)
+	index.Remove(nil, models.SearchKindPhoto, 99)
+	if _, ok := index.postings[models.SearchKindPhoto]["old"]; ok {
+		t.Error("empty posting list was not removed")
+	}
+
+	index.Index(nil, models.SearchKindPhoto, 1, "!!!")
+	if len(index.terms[models.SearchKindPhoto]) != 0 || len(index.postings[models.SearchKindPhoto]) != 0 {
+		t.Errorf("document without terms left postings: %v", index.postings)
+	}
+}
+
+// dryRunDB membuat koneksi GORM yang hanya menyusun SQL tanpa database, tetapi
+// tetap menjalankan hook model
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "u:p@tcp(127.0.0.1:1)/x", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestModelHooksSyncIndex(t *testing.T) {
+	index := NewMemoryIndex()
+	previous := models.Search
+	models.Search = index
+	defer func() { models.Search = previous }()
+
+	db := dryRunDB(t)
+	find := func(kind, query string) []uint {
+		return index.rank(kind, Terms(query))
+	}
+
+	photo := models.Photo{ID: 7, Title: "Sunset", Caption: "at Kuta beach"}
+	if err := db.Create(&photo).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := find(models.SearchKindPhoto, "kuta sun"); !reflect.DeepEqual(got, []uint{7}) {
+		t.Fatalf("after create: %v", got)
+	}
+
+	photo.Caption = "at Sanur beach"
+	if err := db.Save(&photo).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := find(models.SearchKindPhoto, "kuta"); len(got) != 0 {
+		t.Errorf("after update, old caption still found: %v", got)
+	}
+	if got := find(models.SearchKindPhoto, "sanur"); !reflect.DeepEqual(got, []uint{7}) {
+		t.Errorf("after update, new caption not found: %v", got)
+	}
+
+	if err := db.Delete(&photo).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := find(models.SearchKindPhoto, "sunset"); len(got) != 0 {
+		t.Errorf("after delete: %v", got)
+	}
+
+	for _, comment := range []models.Comment{{ID: 1, Message: "great shot"}, {ID: 2, Message: "great colors"}} {
+		if err := db.Create(&comment).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Penghapusan massal tidak membawa ID ke hook, sehingga dokumen tertinggal
+	// sampai RemoveSearchDocuments dipanggil
+	if err := db.Where("photo_id = ?", 7).Delete(&models.Comment{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := find(models.SearchKindComment, "great"); len(got) != 2 {
+		t.Fatalf("bulk delete unexpectedly ran hooks: %v", got)
+	}
+	if err := models.RemoveSearchDocuments(db, models.SearchKindComment, []uint{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if got := find(models.SearchKindComment, "great"); len(got) != 0 {
+		t.Errorf("after RemoveSearchDocuments: %v", got)
+	}
+}
@@ -0,0 +1,43 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MySQLIndex mencari dengan MATCH ... AGAINST pada FULLTEXT index yang dibuat
+// oleh migrasi. MySQL menjaga index tersebut sendiri, jadi Index dan Remove
+// tidak melakukan apa pun.
+//
+// Kata yang lebih pendek dari innodb_ft_min_token_size (default 3) atau
+// termasuk stopword tidak ikut dicari.
+type MySQLIndex struct{}
+
+func (MySQLIndex) Index(tx *gorm.DB, kind string, id uint, text string) error { return nil }
+func (MySQLIndex) Remove(tx *gorm.DB, kind string, id uint) error             { return nil }
+
+func (MySQLIndex) Search(scoped *gorm.DB, kind, query string, limit int) ([]uint, error) {
+	table, ok := tables[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown search kind %q", kind)
+	}
+
+	terms := Terms(query)
+	if len(terms) == 0 {
+		return []uint{}, nil
+	}
+
+	// Setiap kata wajib ada dan boleh berupa awalan, misalnya "sun" cocok dengan "sunset"
+	booleanQuery := "+" + strings.Join(terms, "* +") + "*"
+	match := "MATCH (" + table.columns + ") AGAINST (? IN BOOLEAN MODE)"
+
+	var ids []uint
+	err := scoped.Where(match, booleanQuery).
+		Clauses(clause.OrderBy{Expression: clause.Expr{SQL: match + " DESC", Vars: []interface{}{booleanQuery}, WithoutParentheses: true}}).
+		Limit(limit).
+		Pluck(table.table+".id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,90 @@
+// Package search mencari user, foto, dan komentar dengan kata kunci.
+//
+// Dua implementasi SearchIndex tersedia dan dipilih dengan SEARCH_BACKEND:
+//   - mysql (default): FULLTEXT index pada tabel users, photos, dan comments,
+//     sehingga index dijaga oleh MySQL sendiri
+//   - memory: inverted index di memori proses yang dibangun dari database saat
+//     start dan diperbarui lewat hook model, untuk test dan pengembangan lokal
+//
+// Hasil pencarian selalu diambil dari query yang sudah dibatasi package
+// visibility, jadi index tidak perlu tahu siapa yang boleh melihat dokumen.
+package search
+
+import (
+	"fmt"
+	"os"
+	"unicode"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/models"
+	"golang.org/x/text/cases"
+	"gorm.io/gorm"
+)
+
+// SearchIndex menyimpan dokumen pencarian dan mencarinya dengan kata kunci
+type SearchIndex interface {
+	models.SearchIndexer
+
+	// Search mengembalikan ID dokumen kind yang cocok dengan query, yang paling
+	// relevan lebih dulu. scoped adalah query ke tabel kind yang sudah
+	// dibatasi ke dokumen yang boleh dilihat viewer.
+	Search(scoped *gorm.DB, kind, query string, limit int) ([]uint, error)
+}
+
+// Default adalah index yang dipakai aplikasi, diisi oleh Init.
+var Default SearchIndex = MySQLIndex{}
+
+// Init memilih index dari environment SEARCH_BACKEND (mysql atau memory) dan
+// menghubungkannya ke hook model
+func Init() error {
+	switch backend := os.Getenv("SEARCH_BACKEND"); backend {
+	case "", "mysql":
+		Default = MySQLIndex{}
+	case "memory":
+		index := NewMemoryIndex()
+		if err := index.Rebuild(config.DB); err != nil {
+			return err
+		}
+		Default = index
+	default:
+		return fmt.Errorf("unknown SEARCH_BACKEND %q", backend)
+	}
+
+	models.Search = Default
+	return nil
+}
+
+// tables memetakan jenis dokumen ke tabel dan kolom teksnya
+var tables = map[string]struct {
+	table   string
+	columns string
+}{
+	models.SearchKindUser:    {table: "users", columns: "users.username"},
+	models.SearchKindPhoto:   {table: "photos", columns: "photos.title, photos.caption"},
+	models.SearchKindComment: {table: "comments", columns: "comments.message"},
+}
+
+// Terms memecah teks menjadi kata dalam bentuk case-folded. Semua karakter
+// selain huruf, angka, dan tanda diakritik dianggap pemisah. Tanda diakritik
+// hanya melanjutkan kata, misalnya variation selector setelah emoji tidak
+// memulai kata baru.
+func Terms(text string) []string {
+	folded := cases.Fold().String(text)
+
+	terms := []string{}
+	start := -1
+	for i, r := range folded {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r) || (start >= 0 && unicode.IsMark(r))
+		if inWord && start < 0 {
+			start = i
+		}
+		if !inWord && start >= 0 {
+			terms = append(terms, folded[start:i])
+			start = -1
+		}
+	}
+	if start >= 0 {
+		terms = append(terms, folded[start:])
+	}
+	return terms
+}
@@ -0,0 +1,32 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"   ", []string{}},
+		{"Sunset", []string{"sunset"}},
+		{"Sunset at the BEACH!", []string{"sunset", "at", "the", "beach"}},
+		{"#bali, 2024; beach-house", []string{"bali", "2024", "beach", "house"}},
+		{"new_york", []string{"new", "york"}},
+		{"Straße", []string{"strasse"}},
+		{"Café crème", []string{"café", "crème"}},
+		{"東京 タワー", []string{"東京", "タワー"}},
+		{"हिन्दी", []string{"हिन्दी"}},
+		{"emoji☀\ufe0fsun", []string{"emoji", "sun"}},
+		{"\u0301leading mark", []string{"leading", "mark"}},
+		{"a+b*c\"d", []string{"a", "b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		if got := Terms(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// LocalStorage menyimpan file di filesystem lokal dan menyajikannya lewat
+// BaseURL. Jika Signed aktif, URL berisi tanda tangan HMAC yang kedaluwarsa.
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+	Signed  bool
+	Secret  []byte
+	URLTTL  time.Duration
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return err
+	}
+
+	// Tulis ke file sementara lalu rename agar tidak ada file setengah jadi
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), fullPath)
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(fullPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(fullPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) URL(key string) (string, error) {
+	if _, err := cleanKey(key); err != nil {
+		return "", err
+	}
+
+	u := s.BaseURL + "/" + (&url.URL{Path: key}).EscapedPath()
+	if !s.Signed {
+		return u, nil
+	}
+
+	expires := time.Now().Add(s.URLTTL).Unix()
+	return fmt.Sprintf("%s?expires=%d&signature=%s", u, expires, s.sign(key, expires)), nil
+}
+
+// Verify memeriksa tanda tangan URL yang dibuat oleh URL. Selalu true jika
+// URL bertanda tangan tidak diaktifkan.
+func (s *LocalStorage) Verify(key, expires, signature string) bool {
+	if !s.Signed {
+		return true
+	}
+
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(s.sign(key, exp)))
+}
+
+// Path mengembalikan lokasi file di filesystem untuk disajikan.
+func (s *LocalStorage) Path(key string) (string, error) {
+	return s.path(key)
+}
+
+func (s *LocalStorage) sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	fmt.Fprintf(mac, "%s\n%d", key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(cleaned)), nil
+}
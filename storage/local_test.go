@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCleanKey(t *testing.T) {
+	valid := []string{"a.jpg", "users/1/a.jpg", "a..b/c.jpg"}
+	for _, key := range valid {
+		if _, err := cleanKey(key); err != nil {
+			t.Errorf("cleanKey(%q) = %v, want nil", key, err)
+		}
+	}
+
+	invalid := []string{"", "/etc/passwd", "../secret", "users/../../secret", "users/./a.jpg", "users//a.jpg", "users/", ".."}
+	for _, key := range invalid {
+		if _, err := cleanKey(key); err == nil {
+			t.Errorf("cleanKey(%q) accepted an invalid key", key)
+		}
+	}
+}
+
+func TestLocalVerify(t *testing.T) {
+	s := &LocalStorage{Dir: t.TempDir(), BaseURL: "http://app/media", Signed: true, Secret: []byte("secret"), URLTTL: time.Hour}
+
+	raw, err := s.URL("users/1/a.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+
+	if !s.Verify("users/1/a.jpg", expires, signature) {
+		t.Fatal("Verify rejected a URL created by URL")
+	}
+	if s.Verify("users/1/b.jpg", expires, signature) {
+		t.Error("Verify accepted a signature for a different key")
+	}
+	later, _ := strconv.ParseInt(expires, 10, 64)
+	if s.Verify("users/1/a.jpg", strconv.FormatInt(later+3600, 10), signature) {
+		t.Error("Verify accepted an extended expiry")
+	}
+	if s.Verify("users/1/a.jpg", expires, strings.Repeat("0", len(signature))) {
+		t.Error("Verify accepted a tampered signature")
+	}
+	if s.Verify("users/1/a.jpg", "soon", signature) {
+		t.Error("Verify accepted a non-numeric expiry")
+	}
+
+	past := time.Now().Add(-time.Minute).Unix()
+	if s.Verify("users/1/a.jpg", strconv.FormatInt(past, 10), s.sign("users/1/a.jpg", past)) {
+		t.Error("Verify accepted an expired URL")
+	}
+
+	other := *s
+	other.Secret = []byte("other")
+	if other.Verify("users/1/a.jpg", expires, signature) {
+		t.Error("Verify accepted a signature made with another secret")
+	}
+
+	s.Signed = false
+	if !s.Verify("users/1/a.jpg", "", "") {
+		t.Error("Verify rejected a request while signing is disabled")
+	}
+}
+
+func TestLocalRoundTrip(t *testing.T) {
+	s := &LocalStorage{Dir: t.TempDir(), BaseURL: "http://app/media"}
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "users/1/a.jpg", strings.NewReader("data"), 4, "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+	r, err := s.Get(ctx, "users/1/a.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(r)
+	r.Close()
+	if string(body) != "data" {
+		t.Errorf("Get body = %q", body)
+	}
+
+	if err := s.Delete(ctx, "users/1/a.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "users/1/a.jpg"); err != ErrNotFound {
+		t.Errorf("Get after Delete error = %v, want ErrNotFound", err)
+	}
+
+	if err := s.Put(ctx, "../escape.jpg", strings.NewReader("x"), 1, "image/jpeg"); err == nil {
+		t.Error("Put accepted a traversal key")
+	}
+	if _, err := s.Path("users/../../etc/passwd"); err == nil {
+		t.Error("Path accepted a traversal key")
+	}
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Mikael88/go-mygram/config"
+)
+
+// Storage adalah abstraksi penyimpanan file (misalnya foto yang diunggah).
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL mengembalikan URL publik atau URL bertanda tangan untuk mengakses file
+	URL(key string) (string, error)
+}
+
+// ErrNotFound dikembalikan jika file dengan key tertentu tidak ada.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Default adalah storage yang dipakai aplikasi, diisi oleh Init.
+var Default Storage
+
+// Init memilih implementasi storage dari environment STORAGE_DRIVER (local atau s3).
+func Init() error {
+	urlTTL := time.Duration(config.EnvInt("STORAGE_URL_TTL_MINUTES", 60)) * time.Minute
+
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+
+		// URL local selalu bertanda tangan karena /media tidak memeriksa
+		// visibilitas foto. Tanpa STORAGE_URL_SECRET dipakai secret acak
+		// sehingga URL tidak berlaku lagi setelah server dijalankan ulang.
+		secret := []byte(os.Getenv("STORAGE_URL_SECRET"))
+		if len(secret) == 0 {
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return err
+			}
+			log.Println("STORAGE_URL_SECRET is not set, media URLs will expire on restart")
+		}
+
+		local := &LocalStorage{
+			Dir:     dir,
+			BaseURL: strings.TrimRight(os.Getenv("APP_URL"), "/") + "/media",
+			Signed:  true,
+			Secret:  secret,
+			URLTTL:  urlTTL,
+		}
+
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		Default = local
+	case "s3":
+		s3 := &S3Storage{
+			Endpoint:  strings.TrimRight(os.Getenv("S3_ENDPOINT"), "/"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: os.Getenv("S3_FORCE_PATH_STYLE") == "true",
+			PublicURL: strings.TrimRight(os.Getenv("S3_PUBLIC_URL"), "/"),
+			URLTTL:    urlTTL,
+		}
+		if s3.Endpoint == "" || s3.Bucket == "" || s3.AccessKey == "" || s3.SecretKey == "" {
+			return errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY are required for the s3 storage driver")
+		}
+		if s3.Region == "" {
+			s3.Region = "us-east-1"
+		}
+		Default = s3
+	default:
+		return fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+
+	return nil
+}
+
+// NewKey membuat key acak di bawah prefix agar file tidak bisa ditebak dari
+// ID foto.
+func NewKey(prefix, ext string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return prefix + "/" + hex.EncodeToString(buf) + ext, nil
+}
+
+// cleanKey menolak key yang kosong, absolut, atau keluar dari root storage.
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != key || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,117 @@
+package throttle
+
+import (
+	"time"
+
+	"github.com/Mikael88/go-mygram/config"
+)
+
+// Policy mengatur backoff dan lockout untuk satu jenis key.
+type Policy struct {
+	// FreeAttempts adalah jumlah kegagalan sebelum backoff mulai berlaku
+	FreeAttempts int
+	// BaseDelay adalah jeda setelah kegagalan pertama di luar FreeAttempts,
+	// berlipat dua untuk setiap kegagalan berikutnya hingga MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutThreshold adalah jumlah kegagalan yang memicu lockout sementara
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	// Window adalah berapa lama kegagalan diingat sejak kegagalan terakhir
+	Window time.Duration
+}
+
+// Limiter melacak percobaan gagal dan menentukan kapan percobaan berikutnya boleh dilakukan.
+type Limiter struct {
+	Store  Store
+	Policy Policy
+}
+
+// Check mengembalikan lama waktu tunggu sebelum key boleh mencoba lagi.
+// Nilai nol berarti percobaan diizinkan.
+func (l *Limiter) Check(key string) (time.Duration, error) {
+	entry, ok, err := l.Store.Get(key)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	now := time.Now()
+	until := entry.BlockedUntil
+	if entry.LockedUntil.After(until) {
+		until = entry.LockedUntil
+	}
+	if until.After(now) {
+		return until.Sub(now), nil
+	}
+	return 0, nil
+}
+
+// Fail mencatat percobaan gagal dan mengembalikan waktu tunggu yang berlaku setelahnya.
+func (l *Limiter) Fail(key string) (time.Duration, error) {
+	var wait time.Duration
+	err := l.Store.Update(key, func(entry *Entry) time.Duration {
+		now := time.Now()
+		entry.Failures++
+
+		wait = 0
+		if over := entry.Failures - l.Policy.FreeAttempts; over > 0 {
+			wait = l.Policy.BaseDelay
+			for i := 1; i < over && wait < l.Policy.MaxDelay; i++ {
+				wait *= 2
+			}
+			if wait > l.Policy.MaxDelay {
+				wait = l.Policy.MaxDelay
+			}
+			entry.BlockedUntil = now.Add(wait)
+		}
+
+		if l.Policy.LockoutThreshold > 0 && entry.Failures >= l.Policy.LockoutThreshold {
+			entry.LockedUntil = now.Add(l.Policy.LockoutDuration)
+			entry.Failures = 0
+			wait = l.Policy.LockoutDuration
+		}
+
+		if wait > l.Policy.Window {
+			return wait
+		}
+		return l.Policy.Window
+	})
+	return wait, err
+}
+
+// Reset menghapus riwayat kegagalan key, misalnya setelah login berhasil.
+func (l *Limiter) Reset(key string) error {
+	return l.Store.Delete(key)
+}
+
+// Limiter yang dipakai untuk login, diisi oleh Init.
+var (
+	AccountLimiter *Limiter
+	IPLimiter      *Limiter
+)
+
+// Init menyiapkan limiter login dengan MemoryStore dan konfigurasi dari environment.
+func Init() {
+	InitWithStore(NewMemoryStore(time.Minute))
+}
+
+// InitWithStore menyiapkan limiter login dengan store yang diberikan.
+func InitWithStore(store Store) {
+	AccountLimiter = &Limiter{Store: store, Policy: Policy{
+		FreeAttempts:     config.EnvIntOrZero("LOGIN_ACCOUNT_FREE_ATTEMPTS", 3),
+		BaseDelay:        time.Second,
+		MaxDelay:         time.Duration(config.EnvIntOrZero("LOGIN_MAX_BACKOFF_SECONDS", 60)) * time.Second,
+		LockoutThreshold: config.EnvIntOrZero("LOGIN_ACCOUNT_LOCKOUT_THRESHOLD", 10),
+		LockoutDuration:  time.Duration(config.EnvIntOrZero("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		Window:           time.Hour,
+	}}
+
+	IPLimiter = &Limiter{Store: store, Policy: Policy{
+		FreeAttempts:     config.EnvIntOrZero("LOGIN_IP_FREE_ATTEMPTS", 10),
+		BaseDelay:        time.Second,
+		MaxDelay:         time.Duration(config.EnvIntOrZero("LOGIN_MAX_BACKOFF_SECONDS", 60)) * time.Second,
+		LockoutThreshold: config.EnvIntOrZero("LOGIN_IP_LOCKOUT_THRESHOLD", 50),
+		LockoutDuration:  time.Duration(config.EnvIntOrZero("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		Window:           time.Hour,
+	}}
+}
@@ -0,0 +1,66 @@
+package throttle
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFailBackoffAndLockout(t *testing.T) {
+	l := &Limiter{Store: NewMemoryStore(0), Policy: Policy{
+		FreeAttempts:     2,
+		BaseDelay:        time.Second,
+		MaxDelay:         4 * time.Second,
+		LockoutThreshold: 6,
+		LockoutDuration:  time.Minute,
+		Window:           time.Hour,
+	}}
+
+	want := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, time.Minute}
+	for i, w := range want {
+		wait, err := l.Fail("user")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wait != w {
+			t.Errorf("failure %d: wait = %v, want %v", i+1, wait, w)
+		}
+	}
+
+	if wait, _ := l.Check("user"); wait <= 0 || wait > time.Minute {
+		t.Errorf("Check after lockout = %v, want remaining lockout", wait)
+	}
+
+	if err := l.Reset("user"); err != nil {
+		t.Fatal(err)
+	}
+	if wait, _ := l.Check("user"); wait != 0 {
+		t.Errorf("Check after Reset = %v, want 0", wait)
+	}
+}
+
+func TestFailConcurrentIncrements(t *testing.T) {
+	store := NewMemoryStore(0)
+	l := &Limiter{Store: store, Policy: Policy{FreeAttempts: 1000, Window: time.Hour}}
+
+	const attempts = 200
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := l.Fail("ip"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	entry, ok, err := store.Get("ip")
+	if err != nil || !ok {
+		t.Fatalf("Get = %v, %v", ok, err)
+	}
+	if entry.Failures != attempts {
+		t.Errorf("Failures = %d, want %d", entry.Failures, attempts)
+	}
+}
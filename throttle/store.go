@@ -0,0 +1,93 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// Entry adalah status percobaan gagal untuk satu key (akun atau IP).
+type Entry struct {
+	Failures     int
+	BlockedUntil time.Time
+	LockedUntil  time.Time
+}
+
+// Store menyimpan status throttling. Implementasi bersama (misalnya Redis)
+// bisa dipasang untuk deployment dengan banyak instance.
+type Store interface {
+	Get(key string) (Entry, bool, error)
+	// Update menjalankan fn terhadap entry key secara atomik (entry kosong jika
+	// belum ada atau sudah kedaluwarsa) lalu menyimpannya dengan TTL yang
+	// dikembalikan fn. Percobaan paralel tidak boleh saling menimpa.
+	Update(key string, fn func(entry *Entry) time.Duration) error
+	Delete(key string) error
+}
+
+// MemoryStore adalah Store in-memory untuk satu instance aplikasi.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	entry     Entry
+	expiresAt time.Time
+}
+
+// NewMemoryStore membuat MemoryStore yang membersihkan entry kedaluwarsa secara berkala.
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	s := &MemoryStore{entries: map[string]memoryEntry{}}
+	if cleanupInterval > 0 {
+		go func() {
+			for range time.Tick(cleanupInterval) {
+				s.cleanup()
+			}
+		}()
+	}
+	return s
+}
+
+func (s *MemoryStore) Get(key string) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		return Entry{}, false, nil
+	}
+	return e.entry, true, nil
+}
+
+func (s *MemoryStore) Update(key string, fn func(entry *Entry) time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var entry Entry
+	if e, ok := s.entries[key]; ok && !now.After(e.expiresAt) {
+		entry = e.entry
+	}
+	ttl := fn(&entry)
+	s.entries[key] = memoryEntry{entry: entry, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, e := range s.entries {
+		if now.After(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package trending menghitung hashtag yang sedang ramai dari tag_links dan
+// menyimpannya ke tabel trending_tags.
+//
+// Skor dihitung per window waktu. Setiap pemakaian tag meluruh dengan waktu
+// paruh seperempat panjang window, lalu dirata-rata per user sehingga satu
+// user menyumbang paling banyak 1 ke skor sebuah tag walaupun memakainya
+// berkali-kali. Hanya konten yang bisa dilihat publik yang dihitung.
+package trending
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mikael88/go-mygram/config"
+	"github.com/Mikael88/go-mygram/jobs"
+	"github.com/Mikael88/go-mygram/models"
+	"github.com/Mikael88/go-mygram/visibility"
+	"gorm.io/gorm"
+)
+
+// DefaultWindow adalah window yang dipakai jika tidak dipilih
+const DefaultWindow = "24h"
+
+// Windows adalah window waktu yang dihitung beserta panjangnya
+var Windows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+const computeJob = "compute_trending_tags"
+
+// anonymousViewer dipakai dengan package visibility untuk memilih konten
+// yang terlihat oleh siapa pun
+const anonymousViewer = 0
+
+// Start mendaftarkan job perhitungan trending yang berjalan setiap
+// TRENDING_INTERVAL_SECONDS. Dipanggil sebelum jobs.Start.
+func Start() error {
+	jobs.Register(computeJob, func(ctx context.Context, job models.Job) error {
+		return Compute(config.DB.WithContext(ctx), time.Now())
+	})
+	return jobs.Recurring(computeJob, time.Duration(config.EnvInt("TRENDING_INTERVAL_SECONDS", 300))*time.Second)
+}
+
+// MinUsers adalah jumlah minimal user berbeda agar tag dianggap trending,
+// diatur lewat TRENDING_MIN_USERS
+func MinUsers() int {
+	return config.EnvInt("TRENDING_MIN_USERS", 3)
+}
+
+// Compute menghitung ulang trending_tags untuk semua window. Setiap window
+// menyimpan paling banyak TRENDING_LIMIT tag dengan skor tertinggi.
+func Compute(db *gorm.DB, now time.Time) error {
+	limit := config.EnvInt("TRENDING_LIMIT", 200)
+	for name, length := range Windows {
+		if err := computeWindow(db, name, length, now, limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func computeWindow(db *gorm.DB, name string, length time.Duration, now time.Time, limit int) error {
+	halfLife := (length / 4).Seconds()
+	perUser := db.Model(&models.TagLink{}).
+		Select("tag_links.tag_id, tag_links.user_id, COUNT(*) AS uses, "+
+			"AVG(EXP(-LN(2) * TIMESTAMPDIFF(SECOND, tag_links.created_at, ?) / ?)) AS user_score", now, halfLife).
+		Where("tag_links.created_at > ? AND tag_links.created_at <= ?", now.Add(-length), now).
+		Where(publicLinks(db)).
+		Group("tag_links.tag_id, tag_links.user_id")
+
+	var rows []models.TrendingTag
+	err := db.Table("(?) AS per_user", perUser).
+		Select("tag_id, SUM(user_score) AS score, SUM(uses) AS uses, COUNT(*) AS distinct_users").
+		Group("tag_id").Order("score DESC").Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range rows {
+		rows[i].Window = name
+		rows[i].ComputedAt = now
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("time_window = ?", name).Delete(&models.TrendingTag{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&rows, 100).Error
+	})
+}
+
+// publicLinks membatasi tag_links ke foto dan komentar yang terlihat oleh
+// siapa pun, sehingga tag dari akun privat atau foto unlisted tidak bocor
+func publicLinks(db *gorm.DB) *gorm.DB {
+	db = db.Session(&gorm.Session{NewDB: true})
+	listedPhotos := db.Model(&models.Photo{}).Select("id").Scopes(visibility.ListedPhotos(anonymousViewer))
+	listedComments := db.Model(&models.Comment{}).Select("id").
+		Where("photo_id IN (?) AND user_id NOT IN (?)", listedPhotos, visibility.HiddenOwners(db, anonymousViewer))
+	return db.Where("tag_links.target_type = ? AND tag_links.target_id IN (?)", models.TagTargetPhoto, listedPhotos).
+		Or("tag_links.target_type = ? AND tag_links.target_id IN (?)", models.TagTargetComment, listedComments)
+}
@@ -0,0 +1,100 @@
+// Package visibility membatasi query foto dan komentar ke konten yang boleh
+// dilihat seorang viewer. Semua jalur baca foto dan komentar harus memakai
+// scope dari package ini, misalnya:
+//
+//	config.DB.Scopes(visibility.Photos(userId)).Find(&photos)
+package visibility
+
+import (
+	"github.com/Mikael88/go-mygram/models"
+	"gorm.io/gorm"
+)
+
+// Scope adalah fungsi yang dipakai dengan db.Scopes
+type Scope func(*gorm.DB) *gorm.DB
+
+// HiddenOwners mengembalikan subquery ID user yang kontennya tidak boleh
+// dilihat viewer: akun privat yang bukan milik viewer dan belum memberi akses,
+// serta user yang memblokir atau diblokir viewer.
+func HiddenOwners(db *gorm.DB, viewerID uint) *gorm.DB {
+	db = db.Session(&gorm.Session{NewDB: true})
+	approved := db.Model(&models.AccessRequest{}).Select("account_id").
+		Where("requester_id = ? AND status = ?", viewerID, models.AccessApproved)
+	return db.Model(&models.User{}).Select("id").
+		Where("is_private = ? AND id <> ? AND id NOT IN (?)", true, viewerID, approved).
+		Or("id IN (?)", BlockedUsers(db, viewerID))
+}
+
+// BlockedUsers mengembalikan subquery ID user yang memblokir viewer atau
+// diblokir viewer. Blokir berlaku dua arah.
+func BlockedUsers(db *gorm.DB, viewerID uint) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&models.Block{}).
+		Select("CASE WHEN blocker_id = ? THEN blocked_id ELSE blocker_id END", viewerID).
+		Where("blocker_id = ? OR blocked_id = ?", viewerID, viewerID)
+}
+
+// MutedUsers mengembalikan subquery ID user yang di-mute viewer
+func MutedUsers(db *gorm.DB, viewerID uint) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&models.Mute{}).
+		Select("muted_id").Where("muter_id = ?", viewerID)
+}
+
+// Photos membatasi query tabel photos ke foto yang boleh dibuka viewer secara
+// langsung. Pemilik selalu bisa membuka fotonya sendiri, sedangkan user lain
+// hanya foto published yang tidak only_me dari akun yang terlihat.
+func Photos(viewerID uint) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			"photos.user_id = ? OR (photos.status = ? AND photos.visibility <> ? AND photos.user_id NOT IN (?))",
+			viewerID, models.PhotoPublished, models.PhotoOnlyMe, HiddenOwners(db, viewerID),
+		)
+	}
+}
+
+// ListedPhotos membatasi query tabel photos ke foto yang boleh muncul di daftar
+// dan feed: hanya foto published, dan foto user lain harus public.
+func ListedPhotos(viewerID uint) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("photos.status = ?", models.PhotoPublished).Where(
+			"photos.user_id = ? OR (photos.visibility = ? AND photos.user_id NOT IN (?))",
+			viewerID, models.PhotoPublic, HiddenOwners(db, viewerID),
+		)
+	}
+}
+
+// Comments membatasi query tabel comments ke komentar yang boleh dilihat
+// viewer: penulis komentar harus terlihat dan fotonya harus bisa dibuka.
+func Comments(viewerID uint) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		visiblePhotos := db.Session(&gorm.Session{NewDB: true}).Model(&models.Photo{}).Select("id").
+			Scopes(Photos(viewerID))
+		return db.Where("comments.user_id NOT IN (?)", HiddenOwners(db, viewerID)).
+			Where("comments.photo_id IN (?)", visiblePhotos)
+	}
+}
+
+// Users membatasi daftar user, misalnya follower atau user yang menyukai
+// foto, ke user yang tidak memblokir atau diblokir viewer. column adalah kolom
+// ID user pada tabel yang di-query.
+func Users(viewerID uint, column string) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(column+" NOT IN (?)", BlockedUsers(db, viewerID))
+	}
+}
+
+// Unmuted menyembunyikan konten user yang di-mute viewer dari feed dan daftar
+// komentar. column adalah kolom ID pemilik konten pada tabel yang di-query.
+func Unmuted(viewerID uint, column string) Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(column+" NOT IN (?)", MutedUsers(db, viewerID))
+	}
+}
+
+// CanViewUser mengembalikan true jika viewer boleh melihat konten milik user
+func CanViewUser(db *gorm.DB, viewerID, userID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.User{}).
+		Where("id = ? AND id NOT IN (?)", userID, HiddenOwners(db, viewerID)).
+		Count(&count).Error
+	return count > 0, err
+}